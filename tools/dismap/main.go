@@ -0,0 +1,174 @@
+// Command dismap fingerprints services exposed on a host or network by
+// speaking just enough of each protocol to collect a banner, then
+// matching that banner against its fingerprint database.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gookit/color"
+	flag "github.com/spf13/pflag"
+
+	"github.com/zhzyker/dismap/pkg/checkpoint"
+	"github.com/zhzyker/dismap/pkg/oob"
+	"github.com/zhzyker/dismap/pkg/output"
+	"github.com/zhzyker/dismap/pkg/probe"
+	_ "github.com/zhzyker/dismap/pkg/probe/doh"
+	_ "github.com/zhzyker/dismap/pkg/probe/doq"
+	"github.com/zhzyker/dismap/pkg/rules"
+	"github.com/zhzyker/dismap/pkg/scan"
+)
+
+var (
+	host     = flag.StringP("host", "H", "", "target host or IP")
+	ports    = flag.StringP("port", "p", "80,443", "comma-separated list of ports")
+	timeout  = flag.DurationP("timeout", "t", 3*time.Second, "per-probe timeout")
+	rulesDir = flag.String("rules", "", "directory of YAML fingerprint rules to load in addition to the built-in database")
+	resume   = flag.String("resume", "", "checkpoint file to resume an interrupted scan from; created on first use")
+
+	oobDomain       = flag.String("oob-domain", "", "domain an oob: true rule's {{oob}} token resolves under; required to enable oob: true rules")
+	oobRemote       = flag.String("oob-remote", "", "base URL of a remote oob.Server to poll instead of running an embedded one (see pkg/oob RemoteClient)")
+	oobRemoteSecret = flag.String("oob-remote-secret", "", "shared secret for --oob-remote")
+
+	outputFormat  = flag.String("output-format", "stdout", "comma-separated output sinks: stdout, jsonl, csv, es, webhook")
+	outputFile    = flag.String("output-file", "", "destination file for jsonl/csv sinks; empty means stdout")
+	outputURL     = flag.String("output-url", "", "destination URL for the es/webhook sinks")
+	esIndex       = flag.String("es-index", "dismap", "Elasticsearch index for the es sink")
+	esUsername    = flag.String("es-username", "", "Elasticsearch basic-auth username for the es sink")
+	esPassword    = flag.String("es-password", "", "Elasticsearch basic-auth password for the es sink")
+	esAPIKey      = flag.String("es-api-key", "", "Elasticsearch API key for the es sink (overrides basic auth)")
+	esGZIP        = flag.Bool("es-gzip", false, "gzip-compress the es sink's bulk request body")
+	webhookSecret = flag.String("webhook-secret", "", "HMAC-SHA256 secret used to sign webhook sink requests")
+)
+
+func main() {
+	flag.Parse()
+	if *host == "" {
+		fmt.Println(color.Red.Sprint("dismap: --host is required"))
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	engine, err := rules.NewEngine(*rulesDir)
+	if err != nil {
+		fmt.Println(color.Red.Sprintf("dismap: loading --rules %s: %v", *rulesDir, err))
+		os.Exit(1)
+	}
+
+	if *oobRemote != "" && *oobDomain == "" {
+		fmt.Println(color.Red.Sprint("dismap: --oob-remote requires --oob-domain"))
+		os.Exit(1)
+	}
+	var oobClient oob.Client
+	switch {
+	case *oobRemote != "":
+		oobClient = oob.NewRemoteClient(*oobRemote, *oobRemoteSecret, *oobDomain)
+	case *oobDomain != "":
+		srv := &oob.Server{Domain: *oobDomain}
+		if err := srv.ListenAndServe(); err != nil {
+			fmt.Println(color.Red.Sprintf("dismap: starting oob server: %v", err))
+			os.Exit(1)
+		}
+		defer srv.Close()
+		oobClient = srv
+	}
+	if oobClient != nil {
+		engine.SetOOB(oobClient, *oobDomain)
+	}
+
+	sinks, err := output.Build(output.Config{
+		Formats:       strings.Split(*outputFormat, ","),
+		File:          *outputFile,
+		URL:           *outputURL,
+		ESIndex:       *esIndex,
+		ESUsername:    *esUsername,
+		ESPassword:    *esPassword,
+		ESAPIKey:      *esAPIKey,
+		ESGZIP:        *esGZIP,
+		WebhookSecret: *webhookSecret,
+	})
+	if err != nil {
+		fmt.Println(color.Red.Sprintf("dismap: %v", err))
+		os.Exit(1)
+	}
+	defer closeSinks(sinks)
+
+	opts := scan.Options{Timeout: *timeout, Rules: engine}
+
+	var cp *checkpoint.Store
+	if *resume != "" {
+		fingerprint := checkpoint.Fingerprint(checkpoint.Config{
+			Host:     *host,
+			Ports:    *ports,
+			Timeout:  timeout.String(),
+			RulesDir: *rulesDir,
+		})
+		var prior []output.Record
+		cp, prior, err = checkpoint.Open(*resume, fingerprint)
+		if err != nil {
+			fmt.Println(color.Red.Sprintf("dismap: %v", err))
+			os.Exit(1)
+		}
+		defer cp.Close()
+		opts.Skip = func(tgt probe.Target, probeName string) bool { return cp.Seen(tgt.Host, tgt.Port, probeName) }
+
+		for _, rec := range prior {
+			for _, sink := range sinks {
+				if err := sink.Emit(rec); err != nil {
+					log.Printf("dismap: output sink error: %v", err)
+				}
+			}
+		}
+	}
+
+	s := scan.New(opts)
+
+	// SIGINT during a long scan should leave the checkpoint file (and
+	// whatever's already been flushed to the output sinks) intact
+	// rather than killing the process mid-write.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+scanLoop:
+	for _, portStr := range strings.Split(*ports, ",") {
+		p, err := strconv.Atoi(strings.TrimSpace(portStr))
+		if err != nil {
+			continue
+		}
+		tgt := probe.Target{Host: *host, Port: p}
+		for _, r := range s.Scan(ctx, tgt) {
+			record := output.NewRecord(r)
+			for _, sink := range sinks {
+				// A sink failing (a dead webhook, an unreachable ES
+				// node) must never take down the rest of the scan or
+				// the other sinks.
+				if err := sink.Emit(record); err != nil {
+					log.Printf("dismap: output sink error: %v", err)
+				}
+			}
+			if cp != nil {
+				if err := cp.Record(tgt.Host, tgt.Port, r.Probe, record); err != nil {
+					log.Printf("dismap: checkpoint error: %v", err)
+				}
+			}
+		}
+		if ctx.Err() != nil {
+			break scanLoop
+		}
+	}
+}
+
+func closeSinks(sinks []output.Sink) {
+	for _, sink := range sinks {
+		if err := sink.Close(); err != nil {
+			log.Printf("dismap: closing output sink: %v", err)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// Package checkpoint lets a long scan resume where a previous run left
+// off instead of restarting from scratch. State is kept as an
+// append-only JSONL log: every completed (host, port, probe) tuple is
+// written as its own line with a trailing checksum, so a crash mid-write
+// loses at most the in-flight record rather than corrupting the file.
+package checkpoint
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"sync"
+
+	"github.com/zhzyker/dismap/pkg/output"
+)
+
+// Key identifies one probe run against one endpoint.
+type Key struct {
+	Host  string
+	Port  int
+	Probe string
+}
+
+// entryKind distinguishes the single leading metadata line from the
+// "done" lines that follow it.
+type entryKind string
+
+const (
+	entryMeta entryKind = "meta"
+	entryDone entryKind = "done"
+)
+
+type entry struct {
+	Kind        entryKind      `json:"kind"`
+	Fingerprint string         `json:"fingerprint,omitempty"`
+	Host        string         `json:"host,omitempty"`
+	Port        int            `json:"port,omitempty"`
+	Probe       string         `json:"probe,omitempty"`
+	Record      *output.Record `json:"record,omitempty"`
+}
+
+// Store is a resumable checkpoint log backed by a single file.
+type Store struct {
+	mu   sync.Mutex
+	f    *os.File
+	done map[Key]bool
+}
+
+// Open opens the checkpoint file at path, creating it with fingerprint
+// as its config fingerprint if it doesn't exist yet. If it does exist,
+// Open verifies fingerprint matches the one the file was created with
+// and returns an error if it doesn't, so resuming with incompatible
+// flags fails loudly instead of silently mixing results. It also
+// returns every Record from a prior run so the caller can replay them
+// into this run's output sinks.
+func Open(path string, fingerprint string) (*Store, []output.Record, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("checkpoint: creating %s: %w", path, err)
+		}
+		s := &Store{f: f, done: make(map[Key]bool)}
+		if err := s.append(entry{Kind: entryMeta, Fingerprint: fingerprint}); err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		return s, nil, nil
+	}
+
+	entries, valid, err := readEntries(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(entries) == 0 || entries[0].Kind != entryMeta {
+		return nil, nil, fmt.Errorf("checkpoint: %s has no metadata line, refusing to resume", path)
+	}
+	if entries[0].Fingerprint != fingerprint {
+		return nil, nil, fmt.Errorf("checkpoint: %s was created with different scan options; rerun with the original flags or delete it to start over", path)
+	}
+
+	done := make(map[Key]bool)
+	var prior []output.Record
+	for _, e := range entries[1:] {
+		done[Key{Host: e.Host, Port: e.Port, Probe: e.Probe}] = true
+		if e.Record != nil {
+			prior = append(prior, *e.Record)
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, nil, fmt.Errorf("checkpoint: reopening %s: %w", path, err)
+	}
+	// Cut off whatever an interrupted write left after the last valid
+	// line, so new entries don't land behind bytes readEntries stops at.
+	if info, err := f.Stat(); err == nil && info.Size() > valid {
+		if err := f.Truncate(valid); err != nil {
+			f.Close()
+			return nil, nil, fmt.Errorf("checkpoint: truncating %s: %w", path, err)
+		}
+	}
+	return &Store{f: f, done: done}, prior, nil
+}
+
+// Seen reports whether (host, port, probe) was already recorded as
+// completed in a prior run.
+func (s *Store) Seen(host string, port int, probe string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.done[Key{Host: host, Port: port, Probe: probe}]
+}
+
+// Record marks (host, port, probe) as completed and persists rec
+// alongside it so a future resume can replay it.
+func (s *Store) Record(host string, port int, probe string, rec output.Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.append(entry{Kind: entryDone, Host: host, Port: port, Probe: probe, Record: &rec}); err != nil {
+		return err
+	}
+	s.done[Key{Host: host, Port: port, Probe: probe}] = true
+	return nil
+}
+
+// append writes e as one line and fsyncs before returning, so a
+// completed probe is durable the moment Record returns rather than
+// only on the next periodic flush.
+func (s *Store) append(e entry) error {
+	line, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("checkpoint: encoding entry: %w", err)
+	}
+	sum := crc32.ChecksumIEEE(line)
+	if _, err := fmt.Fprintf(s.f, "%s\t%08x\n", line, sum); err != nil {
+		return fmt.Errorf("checkpoint: writing %s: %w", s.f.Name(), err)
+	}
+	return s.f.Sync()
+}
+
+// Close closes the underlying file.
+func (s *Store) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.f.Close()
+}
+
+// readEntries reads every well-formed line of path in order, stopping
+// at (and discarding) the first line whose checksum doesn't match —
+// that line is the tail of a write that was interrupted mid-flush, and
+// everything durably committed before it is still valid. It also
+// returns the byte length of the valid lines it kept.
+func readEntries(path string) ([]entry, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, fmt.Errorf("checkpoint: opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	var entries []entry
+	var valid int64
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		tab := lastTab(line)
+		if tab < 0 {
+			break
+		}
+		payload, wantSum := line[:tab], line[tab+1:]
+		if fmt.Sprintf("%08x", crc32.ChecksumIEEE([]byte(payload))) != wantSum {
+			break
+		}
+		var e entry
+		if err := json.Unmarshal([]byte(payload), &e); err != nil {
+			break
+		}
+		entries = append(entries, e)
+		valid += int64(len(line)) + 1
+	}
+	if err := sc.Err(); err != nil {
+		return nil, 0, fmt.Errorf("checkpoint: reading %s: %w", path, err)
+	}
+	return entries, valid, nil
+}
+
+func lastTab(s string) int {
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i] == '\t' {
+			return i
+		}
+	}
+	return -1
+}
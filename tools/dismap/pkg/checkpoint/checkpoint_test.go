@@ -0,0 +1,98 @@
+package checkpoint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/zhzyker/dismap/pkg/output"
+)
+
+func TestStoreRecordsAndResumesCompletedTuples(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.ckpt")
+
+	s, prior, err := Open(path, "fp-1")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if len(prior) != 0 {
+		t.Fatalf("expected no prior records on a fresh checkpoint, got %d", len(prior))
+	}
+
+	rec := output.Record{SchemaVersion: output.SchemaVersion, Host: "10.0.0.1", Port: 22, Probe: "tcp", Products: []string{"OpenSSH"}}
+	if err := s.Record("10.0.0.1", 22, "tcp", rec); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	if !s.Seen("10.0.0.1", 22, "tcp") {
+		t.Fatal("expected tuple to be seen after Record")
+	}
+	if s.Seen("10.0.0.1", 23, "tcp") {
+		t.Fatal("did not expect an unrecorded tuple to be seen")
+	}
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	s2, prior2, err := Open(path, "fp-1")
+	if err != nil {
+		t.Fatalf("reopening: %v", err)
+	}
+	defer s2.Close()
+	if !s2.Seen("10.0.0.1", 22, "tcp") {
+		t.Fatal("expected resumed store to remember the completed tuple")
+	}
+	if len(prior2) != 1 || prior2[0].Host != "10.0.0.1" {
+		t.Fatalf("expected the prior record to be replayed, got %+v", prior2)
+	}
+}
+
+func TestOpenRejectsFingerprintMismatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.ckpt")
+
+	s, _, err := Open(path, "fp-1")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	s.Close()
+
+	if _, _, err := Open(path, "fp-2"); err == nil {
+		t.Fatal("expected resuming with a different fingerprint to fail")
+	}
+}
+
+func TestReadEntriesStopsAtCorruptTrailingLine(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scan.ckpt")
+
+	s, _, err := Open(path, "fp-1")
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	rec := output.Record{SchemaVersion: output.SchemaVersion, Host: "10.0.0.1", Port: 22, Probe: "tcp"}
+	if err := s.Record("10.0.0.1", 22, "tcp", rec); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	s.Close()
+
+	// Simulate a crash mid-write: a tail line whose checksum doesn't
+	// match its payload.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatalf("opening for append: %v", err)
+	}
+	if _, err := f.WriteString(`{"kind":"done","host":"10.0.0.1","port":23,"probe":"tcp"}` + "\tdeadbeef\n"); err != nil {
+		t.Fatalf("writing corrupt tail: %v", err)
+	}
+	f.Close()
+
+	s2, _, err := Open(path, "fp-1")
+	if err != nil {
+		t.Fatalf("resuming past a corrupt tail should not fail: %v", err)
+	}
+	defer s2.Close()
+	if !s2.Seen("10.0.0.1", 22, "tcp") {
+		t.Fatal("expected the valid entry before the corrupt tail to survive")
+	}
+	if s2.Seen("10.0.0.1", 23, "tcp") {
+		t.Fatal("did not expect the corrupt tail entry to be recovered")
+	}
+}
@@ -0,0 +1,25 @@
+package checkpoint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Config is the subset of a scan's flags that determine what a
+// checkpoint file's (host, port, probe) tuples actually mean. Fingerprint
+// hashes it so resuming with different --host/--port/--rules/--timeout
+// values is rejected instead of silently producing mixed results.
+type Config struct {
+	Host     string
+	Ports    string
+	Timeout  string
+	RulesDir string
+}
+
+// Fingerprint returns a stable hash of cfg.
+func Fingerprint(cfg Config) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "host=%s\nports=%s\ntimeout=%s\nrules=%s\n", cfg.Host, cfg.Ports, cfg.Timeout, cfg.RulesDir)
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,13 @@
+package fingerprint
+
+// A small sample of the built-in fingerprint database. The real dismap
+// ships several hundred of these; only enough are kept here to exercise
+// the matching path.
+func init() {
+	Register(&Fingerprint{Name: "ssh-openssh", Product: "OpenSSH", Proto: ProtoTCP, Contains: []byte("SSH-2.0-OpenSSH")})
+	Register(&Fingerprint{Name: "ftp-vsftpd", Product: "vsftpd", Proto: ProtoTCP, Contains: []byte("220 (vsFTPd")})
+	Register(&Fingerprint{Name: "redis", Product: "Redis", Proto: ProtoTCP, Regexp: `(?i)-NOAUTH Authentication required|redis_version:`})
+	Register(&Fingerprint{Name: "mysql", Product: "MySQL", Proto: ProtoTCP, Regexp: `(?i)mysql_native_password|MariaDB`})
+	Register(&Fingerprint{Name: "http-nginx", Product: "nginx", Proto: ProtoHTTP, Regexp: `(?i)Server:\s*nginx`})
+	Register(&Fingerprint{Name: "http-apache", Product: "Apache httpd", Proto: ProtoHTTP, Regexp: `(?i)Server:\s*Apache`})
+}
@@ -0,0 +1,73 @@
+// Package fingerprint implements dismap's built-in service fingerprint
+// database. Fingerprints are plain Go structs compiled into the binary;
+// matching is a simple case/byte-contains pass over the banner collected
+// during a probe.
+package fingerprint
+
+import (
+	"bytes"
+	"regexp"
+)
+
+// Protocol identifies the transport a Fingerprint was collected over.
+type Protocol string
+
+const (
+	ProtoTCP  Protocol = "tcp"
+	ProtoUDP  Protocol = "udp"
+	ProtoHTTP Protocol = "http"
+	ProtoTLS  Protocol = "tls"
+)
+
+// Fingerprint describes one hard-coded service signature. Regexp is
+// compiled lazily by the first Match call so zero-value Fingerprints (e.g.
+// built from literals in builtins.go) stay cheap to construct.
+type Fingerprint struct {
+	Name     string
+	Product  string
+	Proto    Protocol
+	Contains []byte
+	Regexp   string
+	re       *regexp.Regexp
+}
+
+// Match reports whether banner satisfies this fingerprint, returning the
+// matched product name on success.
+func (f *Fingerprint) Match(banner []byte) (product string, ok bool) {
+	if len(f.Contains) > 0 && bytes.Contains(banner, f.Contains) {
+		return f.Product, true
+	}
+	if f.Regexp != "" {
+		if f.re == nil {
+			f.re = regexp.MustCompile(f.Regexp)
+		}
+		if f.re.Match(banner) {
+			return f.Product, true
+		}
+	}
+	return "", false
+}
+
+// Builtins holds the hard-coded fingerprint list consulted by Match.
+var Builtins []*Fingerprint
+
+// Register appends a fingerprint to the built-in database. Built-in
+// fingerprint files call this from an init() func.
+func Register(fp *Fingerprint) {
+	Builtins = append(Builtins, fp)
+}
+
+// Match walks the built-in database for the given protocol and returns
+// every fingerprint that matches banner.
+func Match(proto Protocol, banner []byte) []*Fingerprint {
+	var hits []*Fingerprint
+	for _, fp := range Builtins {
+		if fp.Proto != proto {
+			continue
+		}
+		if _, ok := fp.Match(banner); ok {
+			hits = append(hits, fp)
+		}
+	}
+	return hits
+}
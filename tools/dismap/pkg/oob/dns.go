@@ -0,0 +1,74 @@
+package oob
+
+import (
+	"net"
+	"strings"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// serveDNS answers every query on s.dnsConn (A queries with a canned
+// 127.0.0.1 record, all others with an empty answer), after recording an Interaction for whatever token prefixes the
+// queried name (see Subdomain).
+func (s *Server) serveDNS() {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := s.dnsConn.ReadFrom(buf)
+		if err != nil {
+			return // listener closed
+		}
+		s.handleDNSQuery(buf[:n], addr)
+	}
+}
+
+func (s *Server) handleDNSQuery(raw []byte, addr net.Addr) {
+	req := new(dns.Msg)
+	if err := req.Unpack(raw); err != nil || len(req.Question) == 0 {
+		return
+	}
+	question := req.Question[0]
+
+	if token := tokenFromName(question.Name, s.Domain); token != "" {
+		s.record(Interaction{
+			Token:     token,
+			Protocol:  ProtoDNS,
+			SourceIP:  hostOf(addr),
+			Timestamp: time.Now(),
+			Raw:       question.Name,
+		})
+	}
+
+	reply := new(dns.Msg)
+	reply.SetReply(req)
+	if question.Qtype == dns.TypeA {
+		rr, err := dns.NewRR(question.Name + " 60 IN A 127.0.0.1")
+		if err == nil {
+			reply.Answer = append(reply.Answer, rr)
+		}
+	}
+	packed, err := reply.Pack()
+	if err != nil {
+		return
+	}
+	s.dnsConn.WriteTo(packed, addr)
+}
+
+// tokenFromName extracts the token from a query name shaped like
+// "<token>.oob.<domain>.", returning "" if it doesn't match.
+func tokenFromName(name, domain string) string {
+	suffix := ".oob." + domain + "."
+	name = dns.Fqdn(name)
+	if !strings.HasSuffix(name, suffix) {
+		return ""
+	}
+	return strings.TrimSuffix(name, suffix)
+}
+
+func hostOf(addr net.Addr) string {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
@@ -0,0 +1,40 @@
+package oob
+
+import (
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// serveHTTP answers every request on s.httpLn with 200 OK, after
+// recording an Interaction for whatever token prefixes the Host
+// header (see Subdomain).
+func (s *Server) serveHTTP() {
+	srv := &http.Server{Handler: http.HandlerFunc(s.handleHTTPRequest)}
+	srv.Serve(s.httpLn)
+}
+
+func (s *Server) handleHTTPRequest(w http.ResponseWriter, r *http.Request) {
+	host, _, err := net.SplitHostPort(r.Host)
+	if err != nil {
+		host = r.Host
+	}
+	if token := tokenFromName(host, s.Domain); token != "" {
+		s.record(Interaction{
+			Token:     token,
+			Protocol:  ProtoHTTP,
+			SourceIP:  stripPort(r.RemoteAddr),
+			Timestamp: time.Now(),
+			Raw:       r.Method + " " + r.URL.String() + " Host: " + r.Host,
+		})
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func stripPort(hostport string) string {
+	if i := strings.LastIndex(hostport, ":"); i >= 0 {
+		return hostport[:i]
+	}
+	return hostport
+}
@@ -0,0 +1,66 @@
+// Package oob implements dismap's out-of-band interaction subsystem.
+// Some services only reveal themselves by calling back to an external
+// host (SSRF-able proxies, SMTP relays, LDAP referral chasers,
+// JNDI-style listeners). A Client mints a unique token per target,
+// probes inject that token into a payload (a Host header, a HELO
+// hostname, an LDAP URL), and the probe later calls Wait to see
+// whether the target ever reached back out.
+package oob
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Protocol identifies which listener recorded an Interaction.
+type Protocol string
+
+const (
+	ProtoDNS  Protocol = "dns"
+	ProtoHTTP Protocol = "http"
+	ProtoSMTP Protocol = "smtp"
+)
+
+// Interaction is one recorded callback.
+type Interaction struct {
+	Token     string
+	Protocol  Protocol
+	SourceIP  string
+	Timestamp time.Time
+	Raw       string // the request line / query name / SMTP command that carried the token
+}
+
+// Client is how probes mint tokens and check whether a target called
+// back with one. Server (embedded mode) and RemoteClient (polling a
+// remote correlation server) both implement it.
+type Client interface {
+	// NewToken mints a token scoped to target, suitable for embedding
+	// in a payload as the {{oob}} template variable.
+	NewToken(target string) string
+	// Wait blocks up to timeout for at least one interaction carrying
+	// token, returning every interaction seen so far once one arrives
+	// or the timeout elapses.
+	Wait(token string, timeout time.Duration) ([]Interaction, bool)
+}
+
+// NewTokenString returns a random 16-byte hex token. Shared by Server
+// and RemoteClient so tokens minted by either look the same on the
+// wire.
+func NewTokenString() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		// crypto/rand.Read only fails if the platform has no secure
+		// random source, which dismap has no way to recover from.
+		panic(fmt.Sprintf("oob: reading random token: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
+// Subdomain builds the per-token subdomain a probe embeds in
+// DNS-resolvable payloads (Host headers, LDAP URLs, HELO hostnames),
+// e.g. "<token>.oob.example.com" for domain "example.com".
+func Subdomain(domain, token string) string {
+	return fmt.Sprintf("%s.oob.%s", token, domain)
+}
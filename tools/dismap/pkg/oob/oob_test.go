@@ -0,0 +1,38 @@
+package oob
+
+import (
+	"testing"
+	"time"
+)
+
+func TestServerWaitReturnsOnInteraction(t *testing.T) {
+	s := &Server{Domain: "example.com", ring: newRing()}
+	token := s.NewToken("10.0.0.1:443")
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		s.record(Interaction{Token: token, Protocol: ProtoHTTP, SourceIP: "10.0.0.1", Timestamp: time.Now()})
+	}()
+
+	hits, ok := s.Wait(token, time.Second)
+	if !ok || len(hits) != 1 {
+		t.Fatalf("expected one interaction, got %+v (ok=%v)", hits, ok)
+	}
+}
+
+func TestServerWaitTimesOut(t *testing.T) {
+	s := &Server{Domain: "example.com", ring: newRing()}
+	if _, ok := s.Wait("nonexistent", 20*time.Millisecond); ok {
+		t.Fatal("expected Wait to time out")
+	}
+}
+
+func TestTokenFromName(t *testing.T) {
+	got := tokenFromName("abc123.oob.example.com.", "example.com")
+	if got != "abc123" {
+		t.Fatalf("got %q, want abc123", got)
+	}
+	if tokenFromName("unrelated.example.org.", "example.com") != "" {
+		t.Fatal("expected no token match for unrelated domain")
+	}
+}
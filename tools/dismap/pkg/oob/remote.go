@@ -0,0 +1,112 @@
+package oob
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// RemoteClient talks to an oob.Server running elsewhere (e.g. a VPS
+// with 53/80/25 reachable from the target network) over HTTPS, using a
+// shared secret instead of holding the listeners itself. Use this when
+// dismap runs somewhere that can't bind privileged ports or isn't
+// reachable from the scan target directly.
+type RemoteClient struct {
+	BaseURL string // e.g. "https://oob.example.com:8443"
+	Secret  string
+	Domain  string
+
+	httpClient *http.Client
+}
+
+// NewRemoteClient builds a RemoteClient whose HTTP requests time out
+// after 10 seconds. The HTTP client is unexported and has no setter, so
+// that timeout can't be changed from outside the package.
+func NewRemoteClient(baseURL, secret, domain string) *RemoteClient {
+	return &RemoteClient{
+		BaseURL:    baseURL,
+		Secret:     secret,
+		Domain:     domain,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// NewToken asks the remote server to mint a token, falling back to a
+// locally generated one (NewTokenString) if the request fails or the
+// reply carries no token.
+func (c *RemoteClient) NewToken(target string) string {
+	req, err := http.NewRequest(http.MethodPost, c.BaseURL+"/token", nil)
+	if err != nil {
+		return NewTokenString()
+	}
+	c.authenticate(req)
+	q := req.URL.Query()
+	q.Set("target", target)
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return NewTokenString()
+	}
+	defer resp.Body.Close()
+
+	var body struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Token == "" {
+		return NewTokenString()
+	}
+	return body.Token
+}
+
+// Wait polls the remote server's /poll endpoint for token until an
+// interaction is reported or timeout elapses.
+func (c *RemoteClient) Wait(token string, timeout time.Duration) ([]Interaction, bool) {
+	deadline := time.Now().Add(timeout)
+	for {
+		interactions, err := c.poll(token)
+		if err == nil && len(interactions) > 0 {
+			return interactions, true
+		}
+		if time.Now().After(deadline) {
+			return nil, false
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// pollInterval is how often Wait re-checks the remote server. Short
+// enough to stay responsive, long enough not to hammer a shared
+// correlation server during a large scan.
+const pollInterval = 2 * time.Second
+
+func (c *RemoteClient) poll(token string) ([]Interaction, error) {
+	req, err := http.NewRequest(http.MethodGet, c.BaseURL+"/poll", nil)
+	if err != nil {
+		return nil, err
+	}
+	c.authenticate(req)
+	q := req.URL.Query()
+	q.Set("token", token)
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oob: remote server returned %s", resp.Status)
+	}
+
+	var interactions []Interaction
+	if err := json.NewDecoder(resp.Body).Decode(&interactions); err != nil {
+		return nil, err
+	}
+	return interactions, nil
+}
+
+func (c *RemoteClient) authenticate(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+c.Secret)
+}
@@ -0,0 +1,46 @@
+package oob
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// RemoteHandler exposes s over HTTPS for a RemoteClient to poll,
+// guarded by a shared secret compared against each request's
+// Authorization: Bearer header. Mount it on whatever HTTPS listener
+// the remote correlation host runs.
+func (s *Server) RemoteHandler(secret string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", requireSecret(secret, s.handleTokenRequest))
+	mux.HandleFunc("/poll", requireSecret(secret, s.handlePollRequest))
+	return mux
+}
+
+func requireSecret(secret string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if len(got) != len(secret) || subtle.ConstantTimeCompare([]byte(got), []byte(secret)) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) handleTokenRequest(w http.ResponseWriter, r *http.Request) {
+	token := s.NewToken(r.URL.Query().Get("target"))
+	json.NewEncoder(w).Encode(struct {
+		Token string `json:"token"`
+	}{Token: token})
+}
+
+func (s *Server) handlePollRequest(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	interactions := s.ring.get(token)
+	if interactions == nil {
+		interactions = []Interaction{}
+	}
+	json.NewEncoder(w).Encode(interactions)
+}
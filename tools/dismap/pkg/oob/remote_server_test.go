@@ -0,0 +1,52 @@
+package oob
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRequireSecretRejectsWrongOrMissingBearer(t *testing.T) {
+	called := false
+	h := requireSecret("correct-horse", func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/poll", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	w := httptest.NewRecorder()
+	h(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("got status %d, want 401", w.Code)
+	}
+	if called {
+		t.Fatal("next handler must not run without the correct secret")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/poll", nil)
+	w = httptest.NewRecorder()
+	h(w, req)
+
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("missing Authorization header: got status %d, want 401", w.Code)
+	}
+	if called {
+		t.Fatal("next handler must not run without an Authorization header")
+	}
+}
+
+func TestRequireSecretAcceptsCorrectBearer(t *testing.T) {
+	h := requireSecret("correct-horse", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/poll", nil)
+	req.Header.Set("Authorization", "Bearer correct-horse")
+	w := httptest.NewRecorder()
+	h(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200", w.Code)
+	}
+}
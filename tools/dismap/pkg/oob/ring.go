@@ -0,0 +1,65 @@
+package oob
+
+import "sync"
+
+// ringSize bounds how many interactions are kept per token so a noisy
+// or repeatedly-hit token can't grow memory unbounded across a long
+// scan.
+const ringSize = 32
+
+// ring is an in-memory, per-token interaction log with a fixed
+// capacity and a broadcast channel Wait can block on.
+type ring struct {
+	mu    sync.Mutex
+	byTok map[string][]Interaction
+	subs  map[string][]chan struct{}
+}
+
+func newRing() *ring {
+	return &ring{
+		byTok: make(map[string][]Interaction),
+		subs:  make(map[string][]chan struct{}),
+	}
+}
+
+// record appends in to the token's log, trimming to ringSize, and
+// wakes up any Wait callers blocked on that token.
+func (r *ring) record(in Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	log := append(r.byTok[in.Token], in)
+	if len(log) > ringSize {
+		log = log[len(log)-ringSize:]
+	}
+	r.byTok[in.Token] = log
+
+	for _, ch := range r.subs[in.Token] {
+		close(ch)
+	}
+	delete(r.subs, in.Token)
+}
+
+// get returns a snapshot of the interactions recorded for token.
+func (r *ring) get(token string) []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Interaction(nil), r.byTok[token]...)
+}
+
+// subscribe returns token's currently recorded log. If that log is
+// empty, it also registers a channel that record() closes the next
+// time token sees an interaction, all under one lock acquisition so a
+// record() racing between a caller's check and its subscribe can't be
+// missed. Callers must hold no lock when reading from the returned
+// channel, and must not read from a nil one.
+func (r *ring) subscribe(token string) ([]Interaction, chan struct{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if log := r.byTok[token]; len(log) > 0 {
+		return append([]Interaction(nil), log...), nil
+	}
+	ch := make(chan struct{})
+	r.subs[token] = append(r.subs[token], ch)
+	return nil, ch
+}
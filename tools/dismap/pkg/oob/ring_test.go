@@ -0,0 +1,47 @@
+package oob
+
+import "testing"
+
+// TestRingSubscribeReturnsAlreadyRecordedLog pins down the fix for the
+// race ring.subscribe used to leave open: if an interaction is recorded
+// before a caller subscribes, subscribe must hand back that log
+// directly instead of a channel record() has no further reason to
+// close.
+func TestRingSubscribeReturnsAlreadyRecordedLog(t *testing.T) {
+	r := newRing()
+	r.record(Interaction{Token: "tok", Protocol: ProtoDNS})
+
+	log, ch := r.subscribe("tok")
+	if len(log) != 1 {
+		t.Fatalf("expected the already-recorded interaction, got %+v", log)
+	}
+	if ch != nil {
+		t.Fatal("expected no subscription channel once the log is already non-empty")
+	}
+}
+
+// TestRingSubscribeNeverMissesAConcurrentRecord races one record()
+// goroutine against one subscribe() on the same token, 500 times over
+// on a fresh ring each time. Every subscribe() call must observe the interaction either directly (via
+// its returned log) or via its channel closing — never neither, which
+// is exactly the check-then-subscribe gap the atomic subscribe fixes.
+func TestRingSubscribeNeverMissesAConcurrentRecord(t *testing.T) {
+	const attempts = 500
+	for i := 0; i < attempts; i++ {
+		r := newRing()
+		done := make(chan struct{})
+		go func() {
+			r.record(Interaction{Token: "tok", Protocol: ProtoDNS})
+			close(done)
+		}()
+
+		log, ch := r.subscribe("tok")
+		if len(log) > 0 {
+			continue
+		}
+		<-ch // must close once record() above runs; a hang fails the test via -timeout
+		if got := r.get("tok"); len(got) != 1 {
+			t.Fatalf("attempt %d: expected one interaction after channel closed, got %+v", i, got)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package oob
+
+import (
+	"fmt"
+	"log"
+	"net"
+	"time"
+)
+
+// Server is the embedded, single-binary correlation server: it binds
+// DNS (UDP), HTTP and SMTP listeners directly and implements Client
+// against its own in-memory ring. Use this when dismap itself owns the
+// domain's NS/A records, e.g. a pentest box with 53/80/25 reachable
+// from the target network.
+type Server struct {
+	Domain string // e.g. "example.com"; interactions arrive under "<token>.oob.<Domain>"
+
+	ring    *ring
+	dnsConn net.PacketConn
+	httpLn  net.Listener
+	smtpLn  net.Listener
+}
+
+// ListenAndServe starts the DNS, HTTP and SMTP listeners on their
+// standard ports (53, 80, 25) and returns once all three are bound.
+// Binding those ports typically requires root.
+func (s *Server) ListenAndServe() error {
+	s.ring = newRing()
+
+	dnsConn, err := net.ListenPacket("udp", ":53")
+	if err != nil {
+		return fmt.Errorf("oob: binding dns listener: %w", err)
+	}
+	s.dnsConn = dnsConn
+	go s.serveDNS()
+
+	httpLn, err := net.Listen("tcp", ":80")
+	if err != nil {
+		return fmt.Errorf("oob: binding http listener: %w", err)
+	}
+	s.httpLn = httpLn
+	go s.serveHTTP()
+
+	smtpLn, err := net.Listen("tcp", ":25")
+	if err != nil {
+		return fmt.Errorf("oob: binding smtp listener: %w", err)
+	}
+	s.smtpLn = smtpLn
+	go s.serveSMTP()
+
+	return nil
+}
+
+// Close shuts down every listener the Server opened.
+func (s *Server) Close() error {
+	if s.dnsConn != nil {
+		s.dnsConn.Close()
+	}
+	if s.httpLn != nil {
+		s.httpLn.Close()
+	}
+	if s.smtpLn != nil {
+		s.smtpLn.Close()
+	}
+	return nil
+}
+
+// NewToken mints a token for target and logs nothing until an
+// interaction actually carries it.
+func (s *Server) NewToken(target string) string {
+	return NewTokenString()
+}
+
+// Wait implements Client by blocking on the ring's per-token
+// subscription until an interaction lands or timeout elapses. The
+// check for an already-recorded interaction and the subscription for
+// a future one happen atomically (see ring.subscribe), so a callback
+// that arrives between the two can never be missed.
+func (s *Server) Wait(token string, timeout time.Duration) ([]Interaction, bool) {
+	log, ch := s.ring.subscribe(token)
+	if len(log) > 0 {
+		return log, true
+	}
+	select {
+	case <-ch:
+		return s.ring.get(token), true
+	case <-time.After(timeout):
+		return nil, false
+	}
+}
+
+func (s *Server) record(in Interaction) {
+	log.Printf("oob: %s interaction for token %s from %s", in.Protocol, in.Token, in.SourceIP)
+	s.ring.record(in)
+}
@@ -0,0 +1,80 @@
+package oob
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// serveSMTP accepts connections on s.smtpLn and speaks just enough
+// SMTP to let a caller issue HELO/MAIL FROM/RCPT TO/DATA, recording an
+// Interaction for a token found in any of the HELO hostname, the
+// MAIL FROM/RCPT TO addresses, or the DATA body.
+func (s *Server) serveSMTP() {
+	for {
+		conn, err := s.smtpLn.Accept()
+		if err != nil {
+			return // listener closed
+		}
+		go s.handleSMTPConn(conn)
+	}
+}
+
+func (s *Server) handleSMTPConn(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	fmt.Fprintf(conn, "220 dismap-oob ESMTP\r\n")
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		line := scanner.Text()
+		s.recordSMTPLine(conn, line)
+
+		switch {
+		case strings.HasPrefix(strings.ToUpper(line), "QUIT"):
+			fmt.Fprintf(conn, "221 bye\r\n")
+			return
+		case strings.HasPrefix(strings.ToUpper(line), "DATA"):
+			fmt.Fprintf(conn, "354 end with .\r\n")
+			for scanner.Scan() {
+				body := scanner.Text()
+				if body == "." {
+					break
+				}
+				s.recordSMTPLine(conn, body)
+			}
+			fmt.Fprintf(conn, "250 ok\r\n")
+		default:
+			fmt.Fprintf(conn, "250 ok\r\n")
+		}
+	}
+}
+
+func (s *Server) recordSMTPLine(conn net.Conn, line string) {
+	token := firstToken(line, s.Domain)
+	if token == "" {
+		return
+	}
+	s.record(Interaction{
+		Token:     token,
+		Protocol:  ProtoSMTP,
+		SourceIP:  stripPort(conn.RemoteAddr().String()),
+		Timestamp: time.Now(),
+		Raw:       line,
+	})
+}
+
+// firstToken scans line's whitespace/angle-bracket-delimited words for
+// one that carries an oob token under domain.
+func firstToken(line, domain string) string {
+	for _, word := range strings.FieldsFunc(line, func(r rune) bool {
+		return r == ' ' || r == '<' || r == '>' || r == '@' || r == ':'
+	}) {
+		if token := tokenFromName(word, domain); token != "" {
+			return token
+		}
+	}
+	return ""
+}
@@ -0,0 +1,82 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Config selects and configures the sinks Build constructs.
+type Config struct {
+	Formats []string // stdout, jsonl, csv, es, webhook
+	File    string   // destination for jsonl/csv; empty means stdout
+
+	URL           string // destination for es/webhook
+	ESIndex       string
+	ESUsername    string
+	ESPassword    string
+	ESAPIKey      string
+	ESGZIP        bool
+	WebhookSecret string
+}
+
+// Build constructs one Sink per requested format. An unrecognized
+// format fails loudly rather than silently dropping output; a format
+// that needs --output-url and doesn't get one does the same.
+func Build(cfg Config) ([]Sink, error) {
+	if len(cfg.Formats) == 0 {
+		return []Sink{ConsoleSink{}}, nil
+	}
+
+	var sinks []Sink
+	for _, format := range cfg.Formats {
+		switch strings.TrimSpace(format) {
+		case "stdout":
+			sinks = append(sinks, ConsoleSink{})
+		case "jsonl":
+			w, err := openFileOrStdout(cfg.File)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, NewJSONLSink(w))
+		case "csv":
+			w, err := openFileOrStdout(cfg.File)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, NewCSVSink(w))
+		case "es":
+			if cfg.URL == "" {
+				return nil, fmt.Errorf("output: --output-format es requires --output-url")
+			}
+			sinks = append(sinks, NewESSink(cfg.URL, cfg.ESIndex, cfg.ESUsername, cfg.ESPassword, cfg.ESAPIKey, cfg.ESGZIP))
+		case "webhook":
+			if cfg.URL == "" {
+				return nil, fmt.Errorf("output: --output-format webhook requires --output-url")
+			}
+			sinks = append(sinks, NewWebhookSink(cfg.URL, cfg.WebhookSecret))
+		default:
+			return nil, fmt.Errorf("output: unknown --output-format %q", format)
+		}
+	}
+	return sinks, nil
+}
+
+func openFileOrStdout(path string) (io.WriteCloser, error) {
+	if path == "" {
+		return nopCloser{os.Stdout}, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("output: opening %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// nopCloser adapts an io.Writer dismap doesn't own (stdout) to
+// io.WriteCloser so JSONLSink/CSVSink can treat every destination the
+// same way.
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
@@ -0,0 +1,26 @@
+package output
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gookit/color"
+)
+
+// ConsoleSink writes one human-readable, colored line per Record to
+// stdout. It's the default sink when no --output-format is given.
+type ConsoleSink struct{}
+
+func (ConsoleSink) Emit(r Record) error {
+	addr := color.Cyan.Sprintf("%s:%d", r.Host, r.Port)
+	probeName := color.Gray.Sprint(r.Probe)
+	if len(r.Products) == 0 {
+		fmt.Printf("%s [%s] open\n", addr, probeName)
+		return nil
+	}
+	products := color.Green.Sprint(strings.Join(r.Products, ", "))
+	fmt.Printf("%s [%s] %s\n", addr, probeName, products)
+	return nil
+}
+
+func (ConsoleSink) Close() error { return nil }
@@ -0,0 +1,62 @@
+package output
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// csvColumns is the stable column order every row follows, written
+// once as a header before the first Record.
+var csvColumns = []string{"schema_version", "host", "port", "probe", "banner", "products"}
+
+// CSVSink writes one row per Record in csvColumns order, flushing
+// after every row.
+type CSVSink struct {
+	mu     sync.Mutex
+	w      *csv.Writer
+	closer io.Closer
+	header bool
+}
+
+// NewCSVSink wraps w; Close closes w in turn, so callers that don't
+// own w (e.g. os.Stdout) should pass a no-op WriteCloser.
+func NewCSVSink(w io.WriteCloser) *CSVSink {
+	return &CSVSink{w: csv.NewWriter(w), closer: w}
+}
+
+func (s *CSVSink) Emit(r Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.header {
+		if err := s.w.Write(csvColumns); err != nil {
+			return err
+		}
+		s.header = true
+	}
+
+	row := []string{
+		strconv.Itoa(r.SchemaVersion),
+		r.Host,
+		strconv.Itoa(r.Port),
+		r.Probe,
+		r.Banner,
+		strings.Join(r.Products, ";"),
+	}
+	if err := s.w.Write(row); err != nil {
+		return err
+	}
+	s.w.Flush()
+	return s.w.Error()
+}
+
+func (s *CSVSink) Close() error {
+	s.w.Flush()
+	if err := s.w.Error(); err != nil {
+		return err
+	}
+	return s.closer.Close()
+}
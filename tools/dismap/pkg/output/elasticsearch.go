@@ -0,0 +1,102 @@
+package output
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ESSink indexes every Record into Elasticsearch via its `_bulk` NDJSON
+// API, authenticating with either an API key or basic auth and
+// optionally gzip-compressing the request body.
+type ESSink struct {
+	URL      string // e.g. "https://es.example.com:9200"
+	Index    string
+	Username string
+	Password string
+	APIKey   string
+	GZIP     bool
+
+	client *http.Client
+}
+
+// NewESSink builds an ESSink with a sane default HTTP timeout.
+func NewESSink(url, index, username, password, apiKey string, gzipBody bool) *ESSink {
+	return &ESSink{
+		URL:      url,
+		Index:    index,
+		Username: username,
+		Password: password,
+		APIKey:   apiKey,
+		GZIP:     gzipBody,
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (s *ESSink) Emit(r Record) error {
+	meta, err := json.Marshal(map[string]any{"index": map[string]string{"_index": s.Index}})
+	if err != nil {
+		return err
+	}
+	doc, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	body.Write(meta)
+	body.WriteByte('\n')
+	body.Write(doc)
+	body.WriteByte('\n')
+
+	return s.bulk(body.Bytes())
+}
+
+func (s *ESSink) bulk(ndjson []byte) error {
+	var reqBody io.Reader = bytes.NewReader(ndjson)
+	encoding := ""
+	if s.GZIP {
+		var gz bytes.Buffer
+		zw := gzip.NewWriter(&gz)
+		if _, err := zw.Write(ndjson); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+		reqBody = &gz
+		encoding = "gzip"
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimRight(s.URL, "/")+"/_bulk", reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if encoding != "" {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	switch {
+	case s.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+s.APIKey)
+	case s.Username != "":
+		req.SetBasicAuth(s.Username, s.Password)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("output: elasticsearch bulk request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("output: elasticsearch bulk request returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (s *ESSink) Close() error { return nil }
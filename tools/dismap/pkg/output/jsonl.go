@@ -0,0 +1,38 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// JSONLSink writes one JSON object per Record, newline-delimited, and
+// flushes immediately so a tailing consumer never waits on dismap's
+// own buffering.
+type JSONLSink struct {
+	mu sync.Mutex
+	w  io.WriteCloser
+}
+
+// NewJSONLSink wraps w; Close closes w in turn, so callers that don't
+// own w (e.g. os.Stdout) should pass a no-op WriteCloser.
+func NewJSONLSink(w io.WriteCloser) *JSONLSink {
+	return &JSONLSink{w: w}
+}
+
+func (s *JSONLSink) Emit(r Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	_, err = s.w.Write(data)
+	return err
+}
+
+func (s *JSONLSink) Close() error {
+	return s.w.Close()
+}
@@ -0,0 +1,70 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func closableBuffer() (*bytes.Buffer, *nopCloser) {
+	buf := &bytes.Buffer{}
+	return buf, &nopCloser{buf}
+}
+
+func TestJSONLSinkEmitsOneLinePerRecord(t *testing.T) {
+	buf, w := closableBuffer()
+	sink := NewJSONLSink(w)
+
+	rec := Record{SchemaVersion: SchemaVersion, Host: "10.0.0.1", Port: 22, Probe: "tcp", Products: []string{"OpenSSH"}}
+	if err := sink.Emit(rec); err != nil {
+		t.Fatalf("Emit: %v", err)
+	}
+
+	var got Record
+	if err := json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &got); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got.Host != rec.Host || got.Port != rec.Port {
+		t.Fatalf("got %+v, want %+v", got, rec)
+	}
+}
+
+func TestCSVSinkWritesHeaderOnce(t *testing.T) {
+	buf, w := closableBuffer()
+	sink := NewCSVSink(w)
+
+	rec := Record{SchemaVersion: SchemaVersion, Host: "10.0.0.1", Port: 22, Probe: "tcp", Products: []string{"OpenSSH", "sshd"}}
+	if err := sink.Emit(rec); err != nil {
+		t.Fatalf("Emit: %v", err)
+	}
+	if err := sink.Emit(rec); err != nil {
+		t.Fatalf("Emit: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected header + 2 rows, got %d lines: %q", len(lines), buf.String())
+	}
+	if lines[0] != strings.Join(csvColumns, ",") {
+		t.Fatalf("unexpected header: %q", lines[0])
+	}
+	if !strings.Contains(lines[1], "OpenSSH;sshd") {
+		t.Fatalf("expected semicolon-joined products, got %q", lines[1])
+	}
+}
+
+func TestBuildUnknownFormatErrors(t *testing.T) {
+	if _, err := Build(Config{Formats: []string{"carrier-pigeon"}}); err == nil {
+		t.Fatal("expected an error for an unknown output format")
+	}
+}
+
+func TestBuildRemoteFormatsRequireURL(t *testing.T) {
+	if _, err := Build(Config{Formats: []string{"es"}}); err == nil {
+		t.Fatal("expected es format to require --output-url")
+	}
+	if _, err := Build(Config{Formats: []string{"webhook"}}); err == nil {
+		t.Fatal("expected webhook format to require --output-url")
+	}
+}
@@ -0,0 +1,40 @@
+// Package output renders scan results. A Sink is anything that can
+// consume a Record; callers are free to run several side by side (tee
+// colored stdout to a human and JSON Lines to a file, say), and one
+// sink misbehaving must never stop the others or abort the scan.
+package output
+
+import "github.com/zhzyker/dismap/pkg/scan"
+
+// SchemaVersion is carried on every Record so a downstream pipeline
+// parsing dismap's output can detect a shape change and evolve
+// independently of dismap's own release cadence.
+const SchemaVersion = 1
+
+// Record is the stable, sink-facing shape of a scan.Result.
+type Record struct {
+	SchemaVersion int      `json:"schema_version"`
+	Host          string   `json:"host"`
+	Port          int      `json:"port"`
+	Probe         string   `json:"probe"`
+	Banner        string   `json:"banner"`
+	Products      []string `json:"products"`
+}
+
+// NewRecord converts a scan.Result into the shape every Sink consumes.
+func NewRecord(r scan.Result) Record {
+	return Record{
+		SchemaVersion: SchemaVersion,
+		Host:          r.Target.Host,
+		Port:          r.Target.Port,
+		Probe:         r.Probe,
+		Banner:        string(r.Banner),
+		Products:      r.Products,
+	}
+}
+
+// Sink is where dismap sends each scanned Record.
+type Sink interface {
+	Emit(Record) error
+	Close() error
+}
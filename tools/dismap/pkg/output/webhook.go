@@ -0,0 +1,61 @@
+package output
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// signatureHeader carries the webhook body's HMAC-SHA256 so a receiver
+// can verify the POST actually came from this scan's WebhookSink.
+const signatureHeader = "X-Dismap-Signature"
+
+// WebhookSink POSTs each Record as a JSON body to URL, signing it with
+// Secret (if set) so the receiver can verify authenticity.
+type WebhookSink struct {
+	URL    string
+	Secret string
+
+	client *http.Client
+}
+
+// NewWebhookSink builds a WebhookSink with a sane default HTTP
+// timeout.
+func NewWebhookSink(url, secret string) *WebhookSink {
+	return &WebhookSink{URL: url, Secret: secret, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *WebhookSink) Emit(r Record) error {
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(s.Secret))
+		mac.Write(body)
+		req.Header.Set(signatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("output: webhook request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("output: webhook %s returned %s", s.URL, resp.Status)
+	}
+	return nil
+}
+
+func (s *WebhookSink) Close() error { return nil }
@@ -0,0 +1,106 @@
+// Package doh fingerprints DNS-over-HTTPS resolvers (RFC 8484).
+package doh
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/miekg/dns"
+
+	"github.com/zhzyker/dismap/pkg/probe"
+)
+
+func init() {
+	probe.Register(&Probe{})
+}
+
+// Probe sends a canonical A-record query for example.com to a
+// candidate DoH endpoint using both transport variants RFC 8484
+// allows: GET with the query base64url-encoded in the "dns" parameter,
+// and POST with an application/dns-message body. Running both surfaces
+// server quirks that differ between the two code paths.
+type Probe struct{}
+
+func (p *Probe) Name() string { return "doh" }
+
+func (p *Probe) Run(ctx context.Context, tgt probe.Target) (probe.Result, error) {
+	wire, err := canaryQuery()
+	if err != nil {
+		return probe.Result{}, err
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	url := fmt.Sprintf("https://%s:%d/dns-query", tgt.Host, tgt.Port)
+
+	var banner bytes.Buffer
+	if desc, err := doGet(ctx, client, url, wire); err == nil {
+		fmt.Fprintf(&banner, "GET %s\n", desc)
+	}
+	if desc, err := doPost(ctx, client, url, wire); err == nil {
+		fmt.Fprintf(&banner, "POST %s\n", desc)
+	}
+	if banner.Len() == 0 {
+		return probe.Result{}, fmt.Errorf("doh: no response from %s", url)
+	}
+	return probe.Result{Banner: banner.Bytes()}, nil
+}
+
+// canaryQuery builds the wire-format A-record query for example.com
+// used as the probe's canary.
+func canaryQuery() ([]byte, error) {
+	m := new(dns.Msg)
+	m.SetQuestion(dns.Fqdn("example.com"), dns.TypeA)
+	m.RecursionDesired = true
+	return m.Pack()
+}
+
+func doGet(ctx context.Context, client *http.Client, url string, wire []byte) (string, error) {
+	q := base64.RawURLEncoding.EncodeToString(wire)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"?dns="+q, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/dns-message")
+	return describe(client, req)
+}
+
+func doPost(ctx context.Context, client *http.Client, url string, wire []byte) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(wire))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/dns-message")
+	req.Header.Set("Accept", "application/dns-message")
+	return describe(client, req)
+}
+
+// describe issues req and summarizes the negotiated HTTP version,
+// status, Server header, TLS certificate and decoded DNS answer.
+func describe(client *http.Client, req *http.Request) (string, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	reply := new(dns.Msg)
+	parsed := "unparsed"
+	if err := reply.Unpack(body); err == nil {
+		parsed = reply.String()
+	}
+
+	var cert string
+	if resp.TLS != nil && len(resp.TLS.PeerCertificates) > 0 {
+		cert = resp.TLS.PeerCertificates[0].Subject.String()
+	}
+
+	return fmt.Sprintf("proto=%s status=%d server=%q cert=%q answer=%s",
+		resp.Proto, resp.StatusCode, resp.Header.Get("Server"), cert, parsed), nil
+}
@@ -0,0 +1,54 @@
+package probe
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"time"
+)
+
+func init() {
+	Register(&httpProbe{})
+}
+
+// httpProbe sends a minimal HTTP/1.1 GET and returns the raw response
+// (status line, headers and body, read until the connection closes or
+// about 64 KiB has arrived), which is enough for the fingerprint database
+// to identify the server from its Server header and default page.
+type httpProbe struct{}
+
+func (p *httpProbe) Name() string { return "http" }
+
+func (p *httpProbe) Run(ctx context.Context, tgt Target) (Result, error) {
+	d := net.Dialer{}
+	conn, err := d.DialContext(ctx, "tcp", tgt.String())
+	if err != nil {
+		return Result{}, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(3 * time.Second))
+	}
+
+	req := fmt.Sprintf("GET / HTTP/1.1\r\nHost: %s\r\nUser-Agent: dismap\r\nConnection: close\r\n\r\n", tgt.Host)
+	if _, err := conn.Write([]byte(req)); err != nil {
+		return Result{}, err
+	}
+
+	var buf bytes.Buffer
+	io := make([]byte, 4096)
+	for {
+		n, err := conn.Read(io)
+		if n > 0 {
+			buf.Write(io[:n])
+		}
+		if err != nil || buf.Len() > 64*1024 {
+			break
+		}
+	}
+	return Result{Banner: buf.Bytes()}, nil
+}
@@ -0,0 +1,63 @@
+// Package probe defines the pluggable mechanism dismap uses to collect a
+// banner from a target before handing it to pkg/fingerprint for
+// identification.
+package probe
+
+import (
+	"context"
+	"fmt"
+	"net"
+)
+
+// Target is the address a Probe is asked to speak to.
+type Target struct {
+	Host string
+	Port int
+}
+
+func (t Target) String() string {
+	return fmt.Sprintf("%s:%d", t.Host, t.Port)
+}
+
+// Result is what a Probe collects from a single attempt.
+type Result struct {
+	Banner []byte
+	Conn   net.Conn // left open for callers that need to keep talking; nil if the probe closed its connection itself
+}
+
+// Probe speaks just enough of a protocol to collect a banner that
+// pkg/fingerprint can match against. Implementations must respect
+// ctx's deadline and close any connection they open before returning,
+// unless they hand it back via Result.Conn.
+type Probe interface {
+	// Name identifies the probe for logging and the --rules engine.
+	Name() string
+	// Run dials tgt and returns whatever banner bytes it collected.
+	Run(ctx context.Context, tgt Target) (Result, error)
+}
+
+var registry = map[string]Probe{}
+
+// Register adds a probe to the set dismap runs during a scan. Built-in
+// probes call this from an init() func; external packages may call it
+// from their own init() to extend the registry without touching this
+// package.
+func Register(p Probe) {
+	registry[p.Name()] = p
+}
+
+// All returns every registered probe, in registration order is not
+// guaranteed.
+func All() []Probe {
+	out := make([]Probe, 0, len(registry))
+	for _, p := range registry {
+		out = append(out, p)
+	}
+	return out
+}
+
+// Get looks up a probe by name, as registered via Register.
+func Get(name string) (Probe, bool) {
+	p, ok := registry[name]
+	return p, ok
+}
@@ -0,0 +1,37 @@
+package probe
+
+import (
+	"context"
+	"net"
+	"time"
+)
+
+func init() {
+	Register(&tcpProbe{})
+}
+
+// tcpProbe dials the target and reads whatever the service sends
+// unprompted within the connection deadline. This is how dismap
+// fingerprints banner-on-connect services such as SSH and FTP. The
+// connection is handed back open (Result.Conn) so a rule's script:
+// block can keep the conversation going; callers that don't need that
+// must close it themselves.
+type tcpProbe struct{}
+
+func (p *tcpProbe) Name() string { return "tcp" }
+
+func (p *tcpProbe) Run(ctx context.Context, tgt Target) (Result, error) {
+	d := net.Dialer{}
+	conn, err := d.DialContext(ctx, "tcp", tgt.String())
+	if err != nil {
+		return Result{}, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetReadDeadline(deadline)
+	} else {
+		conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	}
+	buf := make([]byte, 4096)
+	n, _ := conn.Read(buf)
+	return Result{Banner: buf[:n], Conn: conn}, nil
+}
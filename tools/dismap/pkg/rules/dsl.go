@@ -0,0 +1,283 @@
+package rules
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// evalDSL evaluates a small boolean expression language used by the
+// "dsl" matcher type. It supports the comparison operators
+// == != < <= > >=, the boolean operators && || !, string and integer
+// literals, the built-in identifiers banner/status/size, any
+// extractor-produced variable by name, and the functions
+// contains(a, b) and len(a). It is intentionally tiny: anything beyond
+// this belongs in a script: block (see pkg/script), not here.
+func evalDSL(expr string, banner []byte, status, size int, vars map[string]string) (bool, error) {
+	p := &dslParser{toks: tokenizeDSL(expr), env: dslEnv{banner: string(banner), status: status, size: size, vars: vars}}
+	v, err := p.parseOr()
+	if err != nil {
+		return false, err
+	}
+	if !p.atEnd() {
+		return false, fmt.Errorf("rules: unexpected trailing input in dsl expression %q", expr)
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("rules: dsl expression %q did not evaluate to a boolean", expr)
+	}
+	return b, nil
+}
+
+type dslEnv struct {
+	banner string
+	status int
+	size   int
+	vars   map[string]string
+}
+
+type dslParser struct {
+	toks []string
+	pos  int
+	env  dslEnv
+}
+
+func (p *dslParser) atEnd() bool { return p.pos >= len(p.toks) }
+
+func (p *dslParser) peek() string {
+	if p.atEnd() {
+		return ""
+	}
+	return p.toks[p.pos]
+}
+
+func (p *dslParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *dslParser) parseOr() (any, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "||" {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = asBool(left) || asBool(right)
+	}
+	return left, nil
+}
+
+func (p *dslParser) parseAnd() (any, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.peek() == "&&" {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = asBool(left) && asBool(right)
+	}
+	return left, nil
+}
+
+func (p *dslParser) parseUnary() (any, error) {
+	if p.peek() == "!" {
+		p.next()
+		v, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return !asBool(v), nil
+	}
+	return p.parseComparison()
+}
+
+func (p *dslParser) parseComparison() (any, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	switch p.peek() {
+	case "==", "!=", "<", "<=", ">", ">=":
+		op := p.next()
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return compare(op, left, right)
+	}
+	return left, nil
+}
+
+func (p *dslParser) parsePrimary() (any, error) {
+	tok := p.next()
+	switch {
+	case tok == "":
+		return nil, fmt.Errorf("rules: unexpected end of dsl expression")
+	case tok == "(":
+		v, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("rules: missing closing paren in dsl expression")
+		}
+		return v, nil
+	case strings.HasPrefix(tok, `"`):
+		return strip(tok), nil
+	case isDSLFunc(tok, p.peek()):
+		return p.parseCall(tok)
+	case isInt(tok):
+		n, _ := strconv.Atoi(tok)
+		return n, nil
+	default:
+		return p.env.lookup(tok), nil
+	}
+}
+
+func isDSLFunc(tok, next string) bool {
+	return next == "(" && (tok == "contains" || tok == "len")
+}
+
+func (p *dslParser) parseCall(name string) (any, error) {
+	p.next() // consume "("
+	var args []any
+	for p.peek() != ")" {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.peek() == "," {
+			p.next()
+		}
+	}
+	p.next() // consume ")"
+	switch name {
+	case "contains":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("rules: contains() takes 2 arguments")
+		}
+		return strings.Contains(fmt.Sprint(args[0]), fmt.Sprint(args[1])), nil
+	case "len":
+		if len(args) != 1 {
+			return nil, fmt.Errorf("rules: len() takes 1 argument")
+		}
+		return len(fmt.Sprint(args[0])), nil
+	}
+	return nil, fmt.Errorf("rules: unknown dsl function %q", name)
+}
+
+func (e dslEnv) lookup(name string) any {
+	switch name {
+	case "banner":
+		return e.banner
+	case "status":
+		return e.status
+	case "size":
+		return e.size
+	}
+	if v, ok := e.vars[name]; ok {
+		return v
+	}
+	return ""
+}
+
+func compare(op string, a, b any) (bool, error) {
+	if an, aok := a.(int); aok {
+		if bn, bok := b.(int); bok {
+			switch op {
+			case "==":
+				return an == bn, nil
+			case "!=":
+				return an != bn, nil
+			case "<":
+				return an < bn, nil
+			case "<=":
+				return an <= bn, nil
+			case ">":
+				return an > bn, nil
+			case ">=":
+				return an >= bn, nil
+			}
+		}
+	}
+	as, bs := fmt.Sprint(a), fmt.Sprint(b)
+	switch op {
+	case "==":
+		return as == bs, nil
+	case "!=":
+		return as != bs, nil
+	default:
+		return false, fmt.Errorf("rules: operator %q is not defined for strings", op)
+	}
+}
+
+func asBool(v any) bool {
+	switch t := v.(type) {
+	case bool:
+		return t
+	case int:
+		return t != 0
+	case string:
+		return t != ""
+	default:
+		return false
+	}
+}
+
+func isInt(s string) bool {
+	if s == "" {
+		return false
+	}
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+// tokenizeDSL splits a dsl expression into operators, parens, string
+// literals, and bareword identifiers/numbers.
+func tokenizeDSL(expr string) []string {
+	var toks []string
+	r := []rune(expr)
+	for i := 0; i < len(r); {
+		switch {
+		case r[i] == ' ' || r[i] == '\t':
+			i++
+		case r[i] == '"':
+			j := i + 1
+			for j < len(r) && r[j] != '"' {
+				j++
+			}
+			toks = append(toks, string(r[i:j+1]))
+			i = j + 1
+		case strings.ContainsRune("(),", r[i]):
+			toks = append(toks, string(r[i]))
+			i++
+		case strings.ContainsRune("=!<>&|", r[i]):
+			if i+1 < len(r) && (r[i+1] == '=' || (r[i] == r[i+1] && (r[i] == '&' || r[i] == '|'))) {
+				toks = append(toks, string(r[i:i+2]))
+				i += 2
+			} else {
+				toks = append(toks, string(r[i]))
+				i++
+			}
+		default:
+			j := i
+			for j < len(r) && !strings.ContainsRune(" \t(),=!<>&|\"", r[j]) {
+				j++
+			}
+			toks = append(toks, string(r[i:j]))
+			i = j
+		}
+	}
+	return toks
+}
@@ -0,0 +1,212 @@
+package rules
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/zhzyker/dismap/pkg/oob"
+	"github.com/zhzyker/dismap/pkg/script"
+)
+
+// defaultScriptTimeout bounds how long a rule's script: block may run
+// against a single connection.
+const defaultScriptTimeout = 5 * time.Second
+
+// defaultPayloadReadTimeout bounds how long Match waits for a response
+// after writing a rule's payload: to conn.
+const defaultPayloadReadTimeout = 3 * time.Second
+
+// defaultOOBWaitTimeout bounds how long Match waits for an oob: rule's
+// target to call back before giving up on it.
+const defaultOOBWaitTimeout = 3 * time.Second
+
+// Engine holds a compiled rule set and evaluates it against collected
+// banners.
+type Engine struct {
+	rules []*Rule
+
+	oobClient oob.Client
+	oobDomain string
+}
+
+// SetOOB wires client and domain into the engine so a rule with
+// oob: true can mint a token, embed it in its payload as {{oob}}, and
+// have Match wait for the target to call back through client instead
+// of matching a banner.
+func (e *Engine) SetOOB(client oob.Client, domain string) {
+	e.oobClient = client
+	e.oobDomain = domain
+}
+
+// NewEngine compiles the rules found under dir. An empty dir yields an
+// Engine with no rules, which Match always reports as a miss on.
+func NewEngine(dir string) (*Engine, error) {
+	if dir == "" {
+		return &Engine{}, nil
+	}
+	loaded, err := LoadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Engine{rules: loaded}, nil
+}
+
+// Match evaluates every loaded rule against banner. conn is passed
+// through for rules whose protocol requires probing the connection
+// directly: a rule with a payload: is skipped unless conn is non-nil,
+// since it has nothing to write to; a rule with a script: block needs
+// conn to carry on its own multi-step conversation and is likewise
+// skipped if conn is nil; a rule with oob: true is skipped unless both
+// conn and an oob.Client (see SetOOB) are available. Rules with none
+// of those only need banner.
+func (e *Engine) Match(conn net.Conn, banner []byte) []RuleHit {
+	var hits []RuleHit
+	for _, rule := range e.rules {
+		vars := map[string]string{}
+
+		var oobToken string
+		if rule.OOB {
+			if conn == nil || e.oobClient == nil {
+				continue
+			}
+			oobToken = e.oobClient.NewToken(conn.RemoteAddr().String())
+			vars["oob"] = oob.Subdomain(e.oobDomain, oobToken)
+		}
+
+		ruleBanner := banner
+		switch {
+		case rule.OOB && rule.Payload != "":
+			// The hit, if any, arrives later on the oob.Client side
+			// channel, not as a reply on conn — don't wait on one.
+			if !writePayload(conn, rule, vars) {
+				continue
+			}
+		case rule.Payload != "":
+			resp, ok := sendPayload(conn, rule, vars)
+			if !ok {
+				continue
+			}
+			ruleBanner = resp
+		}
+
+		for _, ex := range rule.Extractors {
+			for k, v := range ex.Extract(ruleBanner) {
+				vars[k] = v
+			}
+		}
+
+		if rule.OOB {
+			if _, ok := e.oobClient.Wait(oobToken, defaultOOBWaitTimeout); ok {
+				hits = append(hits, RuleHit{Rule: rule, Product: rule.Product, Vars: vars})
+			}
+			continue
+		}
+
+		if rule.Script != "" {
+			if hit, ok := matchScript(conn, rule, ruleBanner, vars); ok {
+				hits = append(hits, hit)
+			}
+			continue
+		}
+
+		status := 0
+		if rule.Protocol == ProtoHTTP {
+			status = httpStatus(ruleBanner)
+		}
+		if !matchesRule(rule, ruleBanner, status, vars) {
+			continue
+		}
+		hits = append(hits, RuleHit{Rule: rule, Product: rule.Product, Vars: vars})
+	}
+	return hits
+}
+
+// writePayload renders rule's payload: (expanding {{Var}} placeholders
+// from vars) and writes it to conn.
+func writePayload(conn net.Conn, rule *Rule, vars map[string]string) bool {
+	if conn == nil {
+		return false
+	}
+	payload, err := RenderPayload(rule.Payload, vars)
+	if err != nil {
+		return false
+	}
+	_, err = conn.Write(payload)
+	return err == nil
+}
+
+// sendPayload writes rule's payload: to conn, then reads and returns
+// whatever comes back so the rest of Match can extract from and match
+// against the response rather than the original banner.
+func sendPayload(conn net.Conn, rule *Rule, vars map[string]string) ([]byte, bool) {
+	if !writePayload(conn, rule, vars) {
+		return nil, false
+	}
+	conn.SetReadDeadline(time.Now().Add(defaultPayloadReadTimeout))
+	buf := make([]byte, 4096)
+	n, err := conn.Read(buf)
+	if err != nil && n == 0 {
+		return nil, false
+	}
+	return buf[:n], true
+}
+
+func matchScript(conn net.Conn, rule *Rule, banner []byte, vars map[string]string) (RuleHit, bool) {
+	if conn == nil {
+		return RuleHit{}, false
+	}
+	res, err := script.Run(context.Background(), conn, banner, rule.Script, defaultScriptTimeout)
+	if err != nil || !res.Matched {
+		return RuleHit{}, false
+	}
+	product := res.Product
+	if product == "" {
+		product = rule.Product
+	}
+	if res.Version != "" {
+		vars["version"] = res.Version
+	}
+	return RuleHit{Rule: rule, Product: product, Vars: vars}, true
+}
+
+func matchesRule(rule *Rule, banner []byte, status int, vars map[string]string) bool {
+	if len(rule.Matchers) == 0 {
+		return false
+	}
+	if rule.MatchersCondition == ConditionAnd {
+		for _, m := range rule.Matchers {
+			if !m.Eval(banner, status, len(banner), vars) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, m := range rule.Matchers {
+		if m.Eval(banner, status, len(banner), vars) {
+			return true
+		}
+	}
+	return false
+}
+
+// httpStatus parses the status code out of an HTTP/1.x response's
+// status line (e.g. "HTTP/1.1 200 OK"), returning 0 if banner doesn't
+// start with one.
+func httpStatus(banner []byte) int {
+	line := banner
+	if i := bytes.IndexByte(banner, '\n'); i >= 0 {
+		line = banner[:i]
+	}
+	fields := bytes.Fields(line)
+	if len(fields) < 2 {
+		return 0
+	}
+	code, err := strconv.Atoi(string(fields[1]))
+	if err != nil {
+		return 0
+	}
+	return code
+}
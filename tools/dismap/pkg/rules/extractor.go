@@ -0,0 +1,94 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// ExtractorType selects how an Extractor pulls a variable out of a
+// banner.
+type ExtractorType string
+
+const (
+	ExtractorRegex ExtractorType = "regex"
+	ExtractorKVal  ExtractorType = "kval"
+	// ExtractorXPath is reserved but deliberately unimplemented: the
+	// type exists in the schema so rule YAML can name it, but compile
+	// rejects any extractor that uses it (see below) rather than
+	// letting it silently extract nothing. Banners this engine deals
+	// with are rarely well-formed XML/HTML, so there's been no driving
+	// use case yet; implement against encoding/xml or golang.org/x/net/html
+	// if one shows up.
+	ExtractorXPath ExtractorType = "xpath"
+)
+
+// Extractor pulls one or more named variables out of a banner. Its
+// output becomes available to the rule's matchers (via {{name}}
+// expansion and the DSL's vars) and is returned in RuleHit.Vars; it is
+// not added to the scan's output Record.
+type Extractor struct {
+	Type  ExtractorType `yaml:"type"`
+	Name  string        `yaml:"name"`
+	Regex []string      `yaml:"regex,omitempty"`
+	KVal  []string      `yaml:"kval,omitempty"`
+	XPath []string      `yaml:"xpath,omitempty"`
+
+	compiledRegex []*regexp.Regexp
+}
+
+func (e *Extractor) compile() error {
+	if e.Type == ExtractorXPath {
+		return fmt.Errorf("rules: extractor %q: type xpath is not supported yet", e.Name)
+	}
+	for _, pattern := range e.Regex {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("rules: invalid regex extractor %q: %w", pattern, err)
+		}
+		e.compiledRegex = append(e.compiledRegex, re)
+	}
+	return nil
+}
+
+// Extract runs the extractor against banner, returning every named
+// capture group it produced (or, for kval, one value per listed key,
+// stored under that key).
+// Type xpath never reaches here: compile rejects it before a rule can
+// load.
+func (e *Extractor) Extract(banner []byte) map[string]string {
+	out := map[string]string{}
+	switch e.Type {
+	case ExtractorRegex:
+		for _, re := range e.compiledRegex {
+			m := re.FindSubmatch(banner)
+			if m == nil {
+				continue
+			}
+			namedGroups := false
+			for i, name := range re.SubexpNames() {
+				if i == 0 || name == "" || i >= len(m) {
+					continue
+				}
+				out[name] = string(m[i])
+				namedGroups = true
+			}
+			// Regexes with no named groups fall back to the
+			// extractor's own Name for the whole match.
+			if !namedGroups && len(m) > 0 {
+				out[e.Name] = string(m[0])
+			}
+		}
+	case ExtractorKVal:
+		lines := strings.Split(string(banner), "\n")
+		for _, key := range e.KVal {
+			for _, line := range lines {
+				prefix := key + ":"
+				if idx := strings.Index(strings.ToLower(line), strings.ToLower(prefix)); idx == 0 {
+					out[key] = strings.TrimSpace(line[len(prefix):])
+				}
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadDir parses every *.yaml/*.yml file under dir into a Rule,
+// compiling its matchers and extractors, and returns the full set. A
+// malformed rule file aborts loading with the offending path in the
+// error so a bad template fails the run instead of scanning silently
+// with partial coverage.
+func LoadDir(dir string) ([]*Rule, error) {
+	var loaded []*Rule
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		ext := filepath.Ext(path)
+		if ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		rule, err := loadFile(path)
+		if err != nil {
+			return fmt.Errorf("rules: %s: %w", path, err)
+		}
+		loaded = append(loaded, rule)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return loaded, nil
+}
+
+func loadFile(path string) (*Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rule Rule
+	if err := yaml.Unmarshal(data, &rule); err != nil {
+		return nil, err
+	}
+	rule.path = path
+	if rule.ID == "" {
+		return nil, fmt.Errorf("rule is missing an id")
+	}
+	if rule.OOB && !strings.Contains(rule.Payload, "{{oob}}") {
+		return nil, fmt.Errorf("rule %q: oob: true requires a payload embedding {{oob}}, otherwise it can never match", rule.ID)
+	}
+	if rule.MatchersCondition == "" {
+		rule.MatchersCondition = ConditionOr
+	}
+	for _, m := range rule.Matchers {
+		if err := m.compile(); err != nil {
+			return nil, err
+		}
+	}
+	for _, e := range rule.Extractors {
+		if err := e.compile(); err != nil {
+			return nil, err
+		}
+	}
+	return &rule, nil
+}
@@ -0,0 +1,146 @@
+package rules
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+)
+
+// Condition joins a list of matcher checks (or a list of matchers
+// within a rule) with boolean and/or semantics.
+type Condition string
+
+const (
+	ConditionAnd Condition = "and"
+	ConditionOr  Condition = "or"
+)
+
+// MatcherType selects how a Matcher inspects a banner.
+type MatcherType string
+
+const (
+	MatcherWord   MatcherType = "word"
+	MatcherRegex  MatcherType = "regex"
+	MatcherBinary MatcherType = "binary"
+	MatcherDSL    MatcherType = "dsl"
+	MatcherStatus MatcherType = "status"
+	MatcherSize   MatcherType = "size"
+)
+
+// Matcher is one check within a Rule. A Matcher carries its own list of
+// words/regex/binary/dsl/status/size entries, joined by Condition; the
+// surrounding Rule joins its Matchers list by its own
+// matchers-condition.
+type Matcher struct {
+	Type      MatcherType `yaml:"type"`
+	Name      string      `yaml:"name,omitempty"`
+	Words     []string    `yaml:"words,omitempty"`
+	Regex     []string    `yaml:"regex,omitempty"`
+	Binary    []string    `yaml:"binary,omitempty"`
+	DSL       []string    `yaml:"dsl,omitempty"`
+	Status    []int       `yaml:"status,omitempty"`
+	Size      []int       `yaml:"size,omitempty"`
+	Condition Condition   `yaml:"condition,omitempty"`
+	Negative  bool        `yaml:"negative,omitempty"`
+
+	compiledRegex  []*regexp.Regexp
+	compiledBinary [][]byte
+}
+
+// compile pre-compiles the matcher's regex and binary entries so Eval
+// never allocates a regexp on the hot path.
+func (m *Matcher) compile() error {
+	for _, pattern := range m.Regex {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("rules: invalid regex matcher %q: %w", pattern, err)
+		}
+		m.compiledRegex = append(m.compiledRegex, re)
+	}
+	for _, raw := range m.Binary {
+		decoded, err := decodeHexEscapes(raw)
+		if err != nil {
+			return fmt.Errorf("rules: invalid binary matcher %q: %w", raw, err)
+		}
+		m.compiledBinary = append(m.compiledBinary, decoded)
+	}
+	for _, expr := range m.DSL {
+		// A dummy run against zero-value inputs can't validate that the
+		// expression matches what the author intends, but it does
+		// exercise the same parser and type rules a real evaluation
+		// would, so a typo'd operator or unbalanced paren fails to load
+		// instead of silently evaluating to false (or, worse, true
+		// under negative: true) on every target.
+		if _, err := evalDSL(expr, nil, 0, 0, nil); err != nil {
+			return fmt.Errorf("rules: invalid dsl matcher %q: %w", expr, err)
+		}
+	}
+	if m.Condition == "" {
+		m.Condition = ConditionOr
+	}
+	return nil
+}
+
+// Eval runs the matcher against banner and the current status/size
+// metadata, applying Negative if set.
+func (m *Matcher) Eval(banner []byte, status, size int, vars map[string]string) bool {
+	result := m.eval(banner, status, size, vars)
+	if m.Negative {
+		return !result
+	}
+	return result
+}
+
+func (m *Matcher) eval(banner []byte, status, size int, vars map[string]string) bool {
+	switch m.Type {
+	case MatcherWord:
+		return evalBool(len(m.Words), m.Condition, func(i int) bool {
+			return bytes.Contains(banner, []byte(expandVars(m.Words[i], vars)))
+		})
+	case MatcherRegex:
+		return evalBool(len(m.compiledRegex), m.Condition, func(i int) bool {
+			return m.compiledRegex[i].Match(banner)
+		})
+	case MatcherBinary:
+		return evalBool(len(m.compiledBinary), m.Condition, func(i int) bool {
+			return bytes.Contains(banner, m.compiledBinary[i])
+		})
+	case MatcherDSL:
+		return evalBool(len(m.DSL), m.Condition, func(i int) bool {
+			ok, err := evalDSL(m.DSL[i], banner, status, size, vars)
+			return err == nil && ok
+		})
+	case MatcherStatus:
+		return evalBool(len(m.Status), m.Condition, func(i int) bool {
+			return m.Status[i] == status
+		})
+	case MatcherSize:
+		return evalBool(len(m.Size), m.Condition, func(i int) bool {
+			return m.Size[i] == size
+		})
+	default:
+		return false
+	}
+}
+
+// evalBool applies cond across n entries of a matcher, short-circuiting
+// the way and/or suggest. An empty entry list is never satisfied.
+func evalBool(n int, cond Condition, check func(i int) bool) bool {
+	if n == 0 {
+		return false
+	}
+	if cond == ConditionAnd {
+		for i := 0; i < n; i++ {
+			if !check(i) {
+				return false
+			}
+		}
+		return true
+	}
+	for i := 0; i < n; i++ {
+		if check(i) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package rules
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var templateVar = regexp.MustCompile(`\{\{(\w+)\}\}`)
+
+// expandVars replaces {{Name}} placeholders with values from vars,
+// leaving unknown placeholders untouched so authoring mistakes are
+// visible in the rendered payload rather than silently blanked.
+func expandVars(s string, vars map[string]string) string {
+	return templateVar.ReplaceAllStringFunc(s, func(m string) string {
+		name := templateVar.FindStringSubmatch(m)[1]
+		if v, ok := vars[name]; ok {
+			return v
+		}
+		return m
+	})
+}
+
+var hexEscape = regexp.MustCompile(`\\x([0-9a-fA-F]{2})`)
+
+// decodeHexEscapes turns \xNN byte escapes embedded in a YAML string
+// into their raw byte values, leaving everything else (including
+// {{Var}} placeholders, expanded separately) as UTF-8 text.
+func decodeHexEscapes(s string) ([]byte, error) {
+	var buf bytes.Buffer
+	rest := s
+	for {
+		loc := hexEscape.FindStringSubmatchIndex(rest)
+		if loc == nil {
+			buf.WriteString(rest)
+			break
+		}
+		buf.WriteString(rest[:loc[0]])
+		b, err := hex.DecodeString(rest[loc[2]:loc[3]])
+		if err != nil {
+			return nil, fmt.Errorf("decoding %q: %w", rest[loc[0]:loc[1]], err)
+		}
+		buf.Write(b)
+		rest = rest[loc[1]:]
+	}
+	return buf.Bytes(), nil
+}
+
+// RenderPayload expands {{Var}} template variables in a rule's payload
+// and decodes any \xNN byte escapes, producing the bytes that should be
+// written to the connection.
+func RenderPayload(payload string, vars map[string]string) ([]byte, error) {
+	return decodeHexEscapes(expandVars(payload, vars))
+}
+
+// strip is a small helper used by the DSL for string-literal tokens.
+func strip(s string) string {
+	return strings.Trim(s, `"`)
+}
@@ -0,0 +1,47 @@
+// Package rules implements dismap's pluggable fingerprint engine. A Rule
+// is a YAML template describing a probe -> matcher -> extractor pipeline,
+// modeled on nuclei's template format. Rules are loaded from a directory
+// at startup (see LoadDir), compiled once, and evaluated against the
+// banner collected by pkg/probe for each target.
+package rules
+
+// Protocol is the transport a Rule's payload should be sent over.
+type Protocol string
+
+const (
+	ProtoTCP  Protocol = "tcp"
+	ProtoUDP  Protocol = "udp"
+	ProtoHTTP Protocol = "http"
+	ProtoTLS  Protocol = "tls"
+)
+
+// Rule is one compiled YAML template.
+type Rule struct {
+	ID                string       `yaml:"id"`
+	Product           string       `yaml:"product,omitempty"`
+	Protocol          Protocol     `yaml:"protocol"`
+	Payload           string       `yaml:"payload,omitempty"`
+	MatchersCondition Condition    `yaml:"matchers-condition,omitempty"`
+	Matchers          []*Matcher   `yaml:"matchers,omitempty"`
+	Extractors        []*Extractor `yaml:"extractors,omitempty"`
+	// Script, if set, is a script: block (see pkg/script) run instead
+	// of the static matchers above for protocols that need a
+	// multi-step conversation to identify (NTLM, SMB negotiate, MySQL
+	// handshake salt, Postgres auth request).
+	Script string `yaml:"script,omitempty"`
+	// OOB marks a rule as a blind out-of-band check: instead of
+	// matching the banner, Engine mints a token, makes it available to
+	// Payload as {{oob}}, and reports a hit only if the target calls
+	// back through the configured oob.Client (see pkg/oob). Requires
+	// an Engine with SetOOB called and a non-nil conn.
+	OOB bool `yaml:"oob,omitempty"`
+
+	path string
+}
+
+// RuleHit is one Rule that matched a target's banner.
+type RuleHit struct {
+	Rule    *Rule
+	Product string
+	Vars    map[string]string
+}
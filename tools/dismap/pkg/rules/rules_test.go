@@ -0,0 +1,167 @@
+package rules
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/zhzyker/dismap/pkg/oob"
+)
+
+// fakeOOBClient is a minimal oob.Client stub: NewToken always returns
+// token, and Wait reports a hit iff it's asked to wait on that same
+// token, simulating a target that actually called back.
+type fakeOOBClient struct {
+	token string
+}
+
+func (f *fakeOOBClient) NewToken(target string) string { return f.token }
+
+func (f *fakeOOBClient) Wait(token string, timeout time.Duration) ([]oob.Interaction, bool) {
+	if token != f.token {
+		return nil, false
+	}
+	return []oob.Interaction{{Token: token, Protocol: oob.ProtoDNS}}, true
+}
+
+func TestEngineMatchWordAndExtractor(t *testing.T) {
+	engine, err := NewEngine("../../rules")
+	if err != nil {
+		t.Fatalf("NewEngine: %v", err)
+	}
+
+	hits := engine.Match(nil, []byte("SSH-2.0-OpenSSH_9.6\r\n"))
+	if len(hits) != 1 || hits[0].Product != "OpenSSH" {
+		t.Fatalf("expected one OpenSSH hit, got %+v", hits)
+	}
+	if hits[0].Vars["version"] != "9.6" {
+		t.Fatalf("expected extracted version 9.6, got %q", hits[0].Vars["version"])
+	}
+}
+
+func TestDSLExpression(t *testing.T) {
+	ok, err := evalDSL(`contains(banner, "mysql_native_password") && len(banner) > 10`,
+		[]byte("mysql_native_password salt"), 0, 0, nil)
+	if err != nil {
+		t.Fatalf("evalDSL: %v", err)
+	}
+	if !ok {
+		t.Fatal("expected dsl expression to match")
+	}
+}
+
+func TestEngineMatchSendsPayloadAndMatchesReply(t *testing.T) {
+	engine := &Engine{rules: []*Rule{{
+		ID:       "redis-test",
+		Product:  "Redis",
+		Protocol: ProtoTCP,
+		Payload:  "INFO\r\n",
+		Matchers: []*Matcher{{Type: MatcherWord, Words: []string{"redis_version:"}}},
+	}}}
+
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go func() {
+		buf := make([]byte, 64)
+		n, _ := server.Read(buf)
+		if string(buf[:n]) != "INFO\r\n" {
+			return
+		}
+		server.Write([]byte("redis_version:7.2.0\r\n"))
+	}()
+
+	hits := engine.Match(client, nil)
+	if len(hits) != 1 || hits[0].Product != "Redis" {
+		t.Fatalf("expected the rendered payload's reply to match, got %+v", hits)
+	}
+}
+
+func TestEngineMatchInjectsOOBTokenAndWaitsForCallback(t *testing.T) {
+	engine := &Engine{rules: []*Rule{{
+		ID:       "oob-test",
+		Product:  "SMTP (oob-confirmed relay)",
+		Protocol: ProtoTCP,
+		Payload:  "EHLO {{oob}}\r\n",
+		OOB:      true,
+	}}}
+	engine.SetOOB(&fakeOOBClient{token: "tok123"}, "example.com")
+
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	written := make(chan string, 1)
+	go func() {
+		buf := make([]byte, 128)
+		n, _ := server.Read(buf)
+		written <- string(buf[:n])
+	}()
+
+	hits := engine.Match(client, nil)
+	if len(hits) != 1 || hits[0].Product != "SMTP (oob-confirmed relay)" {
+		t.Fatalf("expected an oob hit, got %+v", hits)
+	}
+
+	select {
+	case got := <-written:
+		want := "EHLO tok123.oob.example.com\r\n"
+		if got != want {
+			t.Fatalf("got payload %q, want %q", got, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("payload was never written to conn")
+	}
+}
+
+func TestLoadDirRejectsOOBRuleWithoutTokenInPayload(t *testing.T) {
+	dir := t.TempDir()
+	yaml := "id: oob-missing-token\nprotocol: tcp\noob: true\npayload: \"EHLO scanner\\r\\n\"\n"
+	if err := os.WriteFile(filepath.Join(dir, "bad.yaml"), []byte(yaml), 0o644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+
+	if _, err := LoadDir(dir); err == nil {
+		t.Fatal("expected loading an oob: true rule with no {{oob}} in its payload to fail")
+	}
+}
+
+func TestMatcherRejectsMalformedDSLAtCompile(t *testing.T) {
+	m := &Matcher{Type: MatcherDSL, DSL: []string{`contains(banner, "x"`}}
+	if err := m.compile(); err == nil {
+		t.Fatal("expected compiling a matcher with an unbalanced dsl expression to fail")
+	}
+}
+
+func TestLoadDirRejectsRuleWithMalformedDSL(t *testing.T) {
+	dir := t.TempDir()
+	yaml := "id: bad-dsl\nprotocol: tcp\nmatchers:\n  - type: dsl\n    dsl:\n      - 'banner === \"x\"'\n"
+	if err := os.WriteFile(filepath.Join(dir, "bad.yaml"), []byte(yaml), 0o644); err != nil {
+		t.Fatalf("writing fixture: %v", err)
+	}
+
+	if _, err := LoadDir(dir); err == nil {
+		t.Fatal("expected loading a rule with a malformed dsl expression to fail")
+	}
+}
+
+func TestExtractorXPathIsRejectedAtCompile(t *testing.T) {
+	e := &Extractor{Type: ExtractorXPath, Name: "title", XPath: []string{"//title"}}
+	if err := e.compile(); err == nil {
+		t.Fatal("expected compiling an xpath extractor to fail")
+	}
+}
+
+func TestPayloadRendering(t *testing.T) {
+	out, err := RenderPayload(`HELO {{Hostname}}\x0d\x0a`, map[string]string{"Hostname": "scanner"})
+	if err != nil {
+		t.Fatalf("RenderPayload: %v", err)
+	}
+	want := "HELO scanner\r\n"
+	if string(out) != want {
+		t.Fatalf("got %q, want %q", out, want)
+	}
+}
@@ -0,0 +1,105 @@
+// Package scan drives dismap's core loop: for every target/port it runs
+// the registered probes, feeds the collected banner to pkg/fingerprint,
+// and emits a Result.
+package scan
+
+import (
+	"context"
+	"time"
+
+	"github.com/zhzyker/dismap/pkg/fingerprint"
+	"github.com/zhzyker/dismap/pkg/probe"
+	"github.com/zhzyker/dismap/pkg/rules"
+)
+
+// Options controls a Scanner's behaviour.
+type Options struct {
+	Timeout time.Duration
+	Probes  []string      // probe names to run; empty means all registered probes
+	Rules   *rules.Engine // optional user-supplied rule set, consulted alongside pkg/fingerprint
+
+	// Skip, if set, is consulted before running each probe; it returns
+	// true for a (target, probe name) pair that a resumed checkpoint
+	// already completed, so Scan can leave it alone.
+	Skip func(tgt probe.Target, probeName string) bool
+}
+
+// Result is one scanned endpoint's outcome.
+type Result struct {
+	Target   probe.Target
+	Probe    string
+	Banner   []byte
+	Products []string
+}
+
+// Scanner runs probes against targets and fingerprints the responses.
+type Scanner struct {
+	opts Options
+}
+
+// New builds a Scanner from opts, defaulting Timeout to 3s if unset.
+func New(opts Options) *Scanner {
+	if opts.Timeout == 0 {
+		opts.Timeout = 3 * time.Second
+	}
+	return &Scanner{opts: opts}
+}
+
+// Scan runs every configured probe against tgt and returns one Result
+// per probe that produced a banner.
+func (s *Scanner) Scan(ctx context.Context, tgt probe.Target) []Result {
+	probes := s.probes()
+	results := make([]Result, 0, len(probes))
+	for _, p := range probes {
+		if s.opts.Skip != nil && s.opts.Skip(tgt, p.Name()) {
+			continue
+		}
+		pctx, cancel := context.WithTimeout(ctx, s.opts.Timeout)
+		res, err := p.Run(pctx, tgt)
+		cancel()
+		if err != nil || len(res.Banner) == 0 {
+			continue
+		}
+		hits := fingerprint.Match(protoFor(p.Name()), res.Banner)
+		names := make([]string, 0, len(hits))
+		for _, h := range hits {
+			names = append(names, h.Product)
+		}
+		if s.opts.Rules != nil {
+			for _, hit := range s.opts.Rules.Match(res.Conn, res.Banner) {
+				names = append(names, hit.Product)
+			}
+		}
+		if res.Conn != nil {
+			res.Conn.Close()
+		}
+		results = append(results, Result{Target: tgt, Probe: p.Name(), Banner: res.Banner, Products: names})
+	}
+	return results
+}
+
+func (s *Scanner) probes() []probe.Probe {
+	if len(s.opts.Probes) == 0 {
+		return probe.All()
+	}
+	out := make([]probe.Probe, 0, len(s.opts.Probes))
+	for _, name := range s.opts.Probes {
+		if p, ok := probe.Get(name); ok {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func protoFor(probeName string) fingerprint.Protocol {
+	switch probeName {
+	case "http":
+		return fingerprint.ProtoHTTP
+	case "tls":
+		return fingerprint.ProtoTLS
+	case "udp":
+		return fingerprint.ProtoUDP
+	default:
+		return fingerprint.ProtoTCP
+	}
+}
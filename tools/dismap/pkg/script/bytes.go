@@ -0,0 +1,13 @@
+package script
+
+import "encoding/hex"
+
+// bytesAPI is the `bytes` global scripts use to work with the raw byte
+// slices ReadN/ReadUntil hand back.
+type bytesAPI struct{}
+
+// Hex returns data hex-encoded, e.g. for comparing a salt or version
+// byte against a known constant.
+func (*bytesAPI) Hex(data []byte) string {
+	return hex.EncodeToString(data)
+}
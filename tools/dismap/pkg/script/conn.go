@@ -0,0 +1,102 @@
+package script
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// maxReadUntil bounds how far ReadUntil will read looking for its
+// delimiter, so a script waiting on a delimiter the server never sends
+// can't buffer an unbounded amount of data.
+const maxReadUntil = 64 * 1024
+
+// connAPI is the `conn` global scripts use to talk to the probed
+// service. It wraps net.Conn behind a buffered reader so ReadN and
+// ReadUntil can be mixed freely. ctx is Run's execution deadline: every
+// blocking conn operation sets it as a read/write deadline first, so a
+// script that hangs inside a native call (not just the JS bytecode
+// loop goja.Interrupt can preempt) still unblocks when the deadline
+// arrives.
+type connAPI struct {
+	conn net.Conn
+	r    *bufio.Reader
+	ctx  context.Context
+}
+
+// newConnAPI wraps c for script use. prefix is bytes already drained
+// from c by the probe that collected the banner (tcpProbe.Run reads the
+// greeting to populate Result.Banner before handing the connection to
+// the rule engine); without replaying them first, a script's first
+// read would block waiting for bytes the server already sent. ctx must
+// carry a deadline (script.Run guarantees this).
+func newConnAPI(c net.Conn, prefix []byte, ctx context.Context) *connAPI {
+	var r io.Reader = c
+	if len(prefix) > 0 {
+		r = io.MultiReader(bytes.NewReader(prefix), c)
+	}
+	return &connAPI{conn: c, r: bufio.NewReader(r), ctx: ctx}
+}
+
+// deadline returns the earlier of ctx's deadline and want, so a short
+// per-call timeout (ReadN's timeoutMs) can't outlive the script's
+// overall execution budget.
+func (c *connAPI) deadline(want time.Time) time.Time {
+	if d, ok := c.ctx.Deadline(); ok && d.Before(want) {
+		return d
+	}
+	return want
+}
+
+// Write sends data to the connection, bounded by ctx's deadline so a
+// stalled peer can't hang the script past its execution budget.
+func (c *connAPI) Write(data []byte) (int, error) {
+	c.conn.SetWriteDeadline(c.deadline(timeFar()))
+	return c.conn.Write(data)
+}
+
+// ReadN blocks until it has read exactly n bytes or timeoutMs elapses.
+func (c *connAPI) ReadN(n, timeoutMs int) ([]byte, error) {
+	c.conn.SetReadDeadline(c.deadline(time.Now().Add(time.Duration(timeoutMs) * time.Millisecond)))
+	buf := make([]byte, n)
+	read, err := io.ReadFull(c.r, buf)
+	return buf[:read], err
+}
+
+// ReadUntil reads until it has seen delim (any length), returning
+// everything read including the delimiter.
+func (c *connAPI) ReadUntil(delim string) ([]byte, error) {
+	c.conn.SetReadDeadline(c.deadline(timeFar()))
+	var buf []byte
+	d := []byte(delim)
+	for {
+		b, err := c.r.ReadByte()
+		if err != nil {
+			return buf, err
+		}
+		buf = append(buf, b)
+		if len(buf) >= len(d) && string(buf[len(buf)-len(d):]) == delim {
+			return buf, nil
+		}
+		if len(buf) >= maxReadUntil {
+			return buf, fmt.Errorf("script: ReadUntil exceeded %d bytes without seeing %q", maxReadUntil, delim)
+		}
+	}
+}
+
+// timeFar stands in for "no caller-specified deadline" in calls to
+// deadline(), which always clamps to ctx's deadline when one is set.
+func timeFar() time.Time {
+	return time.Now().Add(24 * time.Hour)
+}
+
+// upgrade swaps the underlying connection, used by tlsAPI.Handshake
+// once the TLS handshake completes.
+func (c *connAPI) upgrade(nc net.Conn) {
+	c.conn = nc
+	c.r = bufio.NewReader(nc)
+}
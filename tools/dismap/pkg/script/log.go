@@ -0,0 +1,13 @@
+package script
+
+import "log"
+
+// logAPI is the `log` global scripts use to trace their own
+// multi-step conversations during development.
+type logAPI struct{}
+
+// Debug prints args to dismap's standard logger, prefixed so script
+// output is distinguishable from the rest of a scan's log lines.
+func (*logAPI) Debug(args ...interface{}) {
+	log.Println(append([]interface{}{"[script]"}, args...)...)
+}
@@ -0,0 +1,96 @@
+// Package script embeds a sandboxed JavaScript runtime (goja) so rule
+// authors can write a `script:` block that carries on a multi-step
+// conversation a static matcher can't express — NTLM/SMB negotiate,
+// MySQL's handshake salt, Postgres's auth request. Scripts see four
+// globals: conn, tls, bytes and log (see connAPI, tlsAPI, bytesAPI and
+// logAPI). There is no require()
+// and no filesystem access: the sandbox only ever registers those four
+// globals, never goja_nodejs's module loader.
+package script
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/dop251/goja"
+)
+
+// Result is what a script returns: a plain object shaped like
+// `{matched: true, product: "...", version: "..."}`.
+type Result struct {
+	Matched bool
+	Product string
+	Version string
+}
+
+// maxCallStackSize bounds goja's stack depth so a buggy or hostile
+// script can't exhaust memory through unbounded recursion. goja has no
+// separate heap cap, so this is the practical ceiling dismap enforces.
+const maxCallStackSize = 256
+
+// Run compiles and executes src against conn. banner is whatever the
+// probe already read off conn before handing it to the script (the
+// bytes behind Result.Banner); it's replayed to conn's first reads so a
+// script still sees the greeting the probe collected, rather than
+// blocking on bytes the server already sent and won't send again. If
+// ctx carries no deadline, timeout bounds execution instead; either
+// way, the script is interrupted the instant its wall-clock budget
+// runs out.
+func Run(ctx context.Context, conn net.Conn, banner []byte, src string, timeout time.Duration) (Result, error) {
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	rt := goja.New()
+	rt.SetMaxCallStackSize(maxCallStackSize)
+
+	c := newConnAPI(conn, banner, ctx)
+	rt.Set("conn", c)
+	rt.Set("tls", newTLSAPI(c))
+	rt.Set("bytes", &bytesAPI{})
+	rt.Set("log", &logAPI{})
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-ctx.Done():
+			rt.Interrupt("script: execution deadline exceeded")
+		case <-stop:
+		}
+	}()
+
+	v, err := rt.RunString(src)
+	if err != nil {
+		return Result{}, fmt.Errorf("script: %w", err)
+	}
+	return resultFromValue(rt, v)
+}
+
+// resultFromValue reads the {matched, product, version} object a
+// script returns. It reads properties directly (rather than relying on
+// goja's ExportTo struct mapping) so the JS-facing shape can stay
+// lowercase while the Go-facing API (conn.ReadN, tls.Handshake, ...)
+// keeps its Go capitalization.
+func resultFromValue(rt *goja.Runtime, v goja.Value) (Result, error) {
+	obj, ok := v.(*goja.Object)
+	if !ok {
+		return Result{}, fmt.Errorf("script: return value must be an object shaped like {matched, product, version}")
+	}
+	return Result{
+		Matched: obj.Get("matched") != nil && obj.Get("matched").ToBoolean(),
+		Product: stringProp(obj, "product"),
+		Version: stringProp(obj, "version"),
+	}, nil
+}
+
+func stringProp(obj *goja.Object, name string) string {
+	if v := obj.Get(name); v != nil {
+		return v.String()
+	}
+	return ""
+}
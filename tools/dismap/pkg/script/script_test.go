@@ -0,0 +1,97 @@
+package script
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestRunReadsAndMatches(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	go func() {
+		server.Write([]byte("hello\n"))
+	}()
+
+	src := `
+		var line = conn.ReadUntil("\n");
+		var text = String.fromCharCode.apply(null, line);
+		({matched: text.indexOf("hello") === 0, product: "demo", version: bytes.Hex(line)});
+	`
+	res, err := Run(context.Background(), client, nil, src, time.Second)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !res.Matched || res.Product != "demo" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
+
+func TestRunSeesBannerBytesAlreadyDrainedByTheProbe(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	// Simulate tcpProbe.Run: it already consumed the greeting off the
+	// wire into banner before the rule engine (and this script) ever
+	// sees the connection, so nothing more arrives here.
+	banner := []byte("220-mysql greeting\n")
+
+	src := `
+		var line = conn.ReadUntil("\n");
+		var text = String.fromCharCode.apply(null, line);
+		({matched: text.indexOf("220-mysql") === 0, product: "mysql"});
+	`
+	res, err := Run(context.Background(), client, banner, src, time.Second)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if !res.Matched || res.Product != "mysql" {
+		t.Fatalf("expected the script to see the already-drained banner, got %+v", res)
+	}
+}
+
+func TestRunInterruptsOnDeadline(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	src := `conn.ReadN(1, 50); ({matched: true});`
+	_, err := Run(context.Background(), client, nil, src, 20*time.Millisecond)
+	if err == nil {
+		t.Fatal("expected Run to fail once its deadline elapsed")
+	}
+}
+
+// TestRunUnblocksAStalledWrite pins down the failure mode a sandboxed
+// script must never hit: a conn.Write into a peer that never reads
+// (net.Pipe is unbuffered and synchronous) has to return once the
+// script's execution deadline elapses, not hang forever. rt.Interrupt()
+// alone can't do this — it only preempts goja's bytecode loop, not a
+// goroutine parked inside net.Conn.Write — so this exercises the
+// SetWriteDeadline wiring in connAPI.Write instead.
+func TestRunUnblocksAStalledWrite(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+	// server never reads, so client.Write blocks until its deadline.
+
+	src := `conn.Write([0]); ({matched: true});`
+	done := make(chan error, 1)
+	go func() {
+		_, err := Run(context.Background(), client, nil, src, 50*time.Millisecond)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("expected Run to fail once the stalled write's deadline elapsed")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Run did not return within 2s of a 50ms deadline: the stalled write was never unblocked")
+	}
+}
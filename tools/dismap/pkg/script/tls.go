@@ -0,0 +1,45 @@
+package script
+
+import (
+	"crypto/tls"
+)
+
+// tlsAPI is the `tls` global scripts use to upgrade the connection
+// mid-conversation, mirroring a STARTTLS-style negotiation.
+type tlsAPI struct {
+	conn *connAPI
+}
+
+func newTLSAPI(c *connAPI) *tlsAPI {
+	return &tlsAPI{conn: c}
+}
+
+// tlsOptions is what a script passes to Handshake, e.g.
+// `tls.Handshake({ServerName: "example.com", InsecureSkipVerify: true})`.
+type tlsOptions struct {
+	ServerName         string
+	InsecureSkipVerify bool
+}
+
+// tlsState is what Handshake returns on success.
+type tlsState struct {
+	Version            uint16
+	NegotiatedProtocol string
+}
+
+// Handshake upgrades the script's connection to TLS in place and
+// returns the negotiated state. It hands conn's ctx to HandshakeContext
+// so a stalled peer is cut off at the script's execution deadline
+// rather than hanging the handshake indefinitely.
+func (t *tlsAPI) Handshake(opts tlsOptions) (tlsState, error) {
+	conn := tls.Client(t.conn.conn, &tls.Config{
+		ServerName:         opts.ServerName,
+		InsecureSkipVerify: opts.InsecureSkipVerify,
+	})
+	if err := conn.HandshakeContext(t.conn.ctx); err != nil {
+		return tlsState{}, err
+	}
+	t.conn.upgrade(conn)
+	state := conn.ConnectionState()
+	return tlsState{Version: state.Version, NegotiatedProtocol: state.NegotiatedProtocol}, nil
+}